@@ -0,0 +1,122 @@
+package allcache
+
+// FrequencyAdmitter - TinyLFU admission: candidate is admitted if it was read more often than the victim.
+// Frequencies are estimated by count-min sketch of Get calls, so it fits read-through caches.
+// @see https://arxiv.org/abs/1512.00727
+type FrequencyAdmitter[K comparable] struct {
+	sketch *countMinSketch
+	hasher KeyHasher[K]
+}
+
+// NewFrequencyAdmitter - capacity is the expected number of cached keys, hasher is HashKey if nil
+func NewFrequencyAdmitter[K comparable](capacity uint64, hasher KeyHasher[K]) *FrequencyAdmitter[K] {
+	if nil == hasher {
+		hasher = HashKey[K]
+	}
+	return &FrequencyAdmitter[K]{sketch: newCountMinSketch(capacity), hasher: hasher}
+}
+
+func (a *FrequencyAdmitter[K]) Record(key K) {
+	a.sketch.increment(a.hasher(key))
+}
+
+func (a *FrequencyAdmitter[K]) Admit(candidate K, _ uint64, victim K, hasVictim bool) bool {
+	if !hasVictim {
+		return true
+	}
+	return a.sketch.estimate(a.hasher(candidate)) > a.sketch.estimate(a.hasher(victim))
+}
+
+// Frequency - estimated number of reads of key
+func (a *FrequencyAdmitter[K]) Frequency(key K) uint8 {
+	return a.sketch.estimate(a.hasher(key))
+}
+
+const (
+	doorkeeperBitsPerKey = 10
+	doorkeeperHashes     = 3
+)
+
+// DoorkeeperAdmitter - "second hit" admission: when cache is full, key is admitted only if it was put before.
+// Seen keys are remembered by bloom filter, which is cleared after capacity keys were added,
+// so one-hit wonders never push out cached keys.
+type DoorkeeperAdmitter[K comparable] struct {
+	bits     []uint64
+	mask     uint64
+	added    uint64
+	capacity uint64
+	hasher   KeyHasher[K]
+}
+
+// NewDoorkeeperAdmitter - capacity is the number of keys remembered between resets, hasher is HashKey if nil
+func NewDoorkeeperAdmitter[K comparable](capacity uint64, hasher KeyHasher[K]) *DoorkeeperAdmitter[K] {
+	if nil == hasher {
+		hasher = HashKey[K]
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	size := uint64(64)
+	for size < capacity*doorkeeperBitsPerKey {
+		size <<= 1
+	}
+	return &DoorkeeperAdmitter[K]{
+		bits:     make([]uint64, size/64),
+		mask:     size - 1,
+		capacity: capacity,
+		hasher:   hasher,
+	}
+}
+
+func (a *DoorkeeperAdmitter[K]) Record(K) {}
+
+func (a *DoorkeeperAdmitter[K]) Admit(candidate K, _ uint64, _ K, hasVictim bool) bool {
+	if !hasVictim {
+		return true
+	}
+	h := a.hasher(candidate)
+	if a.contains(h) {
+		return true
+	}
+	a.add(h)
+	return false
+}
+
+func (a *DoorkeeperAdmitter[K]) contains(h uint64) bool {
+	for i := uint64(0); i < doorkeeperHashes; i++ {
+		b := (h + i*(h>>32|1)) & a.mask
+		if a.bits[b/64]&(1<<(b%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *DoorkeeperAdmitter[K]) add(h uint64) {
+	if a.added >= a.capacity {
+		for i := range a.bits {
+			a.bits[i] = 0
+		}
+		a.added = 0
+	}
+	for i := uint64(0); i < doorkeeperHashes; i++ {
+		b := (h + i*(h>>32|1)) & a.mask
+		a.bits[b/64] |= 1 << (b % 64)
+	}
+	a.added += 1
+}
+
+// SizeAdmitter - items heavier than maxWeight are not cached, so a few large items can't flush many small ones
+type SizeAdmitter[K comparable] struct {
+	maxWeight uint64
+}
+
+func NewSizeAdmitter[K comparable](maxWeight uint64) *SizeAdmitter[K] {
+	return &SizeAdmitter[K]{maxWeight: maxWeight}
+}
+
+func (a *SizeAdmitter[K]) Record(K) {}
+
+func (a *SizeAdmitter[K]) Admit(_ K, weight uint64, _ K, _ bool) bool {
+	return weight <= a.maxWeight
+}
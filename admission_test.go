@@ -0,0 +1,145 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+type suiteAdmission struct {
+	suite.Suite
+}
+
+func TestAdmission(t *testing.T) {
+	suite.Run(t, new(suiteAdmission))
+}
+
+func (s *suiteAdmission) TestSketch() {
+	c := newCountMinSketch(16)
+	s.Equal(uint64(160), c.sampleSize)
+	h := HashKey("a")
+	for i := 0; i < 20; i++ {
+		c.increment(h)
+	}
+	//counters saturate
+	s.Equal(uint8(sketchMaxCount), c.estimate(h))
+	s.Equal(uint8(0), c.estimate(HashKey("b")))
+
+	c.reset()
+	s.Equal(uint8(sketchMaxCount/2), c.estimate(h))
+	s.Equal(uint64(10), c.samples)
+}
+
+func (s *suiteAdmission) TestFrequencyAdmitter() {
+	a := NewFrequencyAdmitter[string](64, nil)
+	c := newNtsStore[string, int](newNtsLRU[string](), 2, nil)
+	c.admitter = a
+	c.put("1", 1)
+	c.put("2", 2)
+	c.get("1", 0)
+	c.get("2", 0)
+	c.get("2", 0)
+
+	//"3" was never read, "1" stays
+	c.put("3", 3)
+	_, ok := c.get("1", 0)
+	s.True(ok)
+	s.Equal(uint64(1), c.stats.Rejections)
+
+	//read-through of popular key: every miss is counted
+	for i := 0; i < 3; i++ {
+		if _, ok = c.get("3", 0); !ok {
+			c.put("3", 3)
+		}
+	}
+	_, ok = c.get("3", 0)
+	s.True(ok)
+	s.Equal(uint8(4), a.Frequency("3"))
+}
+
+func (s *suiteAdmission) TestDoorkeeperAdmitter() {
+	c := newNtsStore[string, int](newNtsLRU[string](), 2, nil)
+	c.admitter = NewDoorkeeperAdmitter[string](2, nil)
+	//free space is given without second hit
+	c.put("1", 1)
+	c.put("2", 2)
+	c.put("3", 3)
+	_, ok := c.get("3", 0)
+	s.False(ok)
+	c.put("3", 3)
+	_, ok = c.get("3", 0)
+	s.True(ok)
+	_, ok = c.get("1", 0)
+	s.False(ok)
+
+	//filter is cleared after capacity keys
+	c.put("4", 4)
+	c.put("5", 5)
+	c.put("3", 3)
+	c.put("6", 6)
+	c.put("4", 4)
+	_, ok = c.get("4", 0)
+	s.False(ok)
+}
+
+func (s *suiteAdmission) TestSizeAdmitter() {
+	c := newNtsStore[string, int](newNtsLRU[string](), 10, func(v int) uint64 { return uint64(v) })
+	c.admitter = NewSizeAdmitter[string](5)
+	c.put("small", 5)
+	c.put("large", 6)
+	_, ok := c.get("large", 0)
+	s.False(ok)
+	_, ok = c.get("small", 0)
+	s.True(ok)
+	s.Equal(uint64(5), c.weight)
+}
+
+func (s *suiteAdmission) TestHashKey() {
+	s.Equal(HashKey("1"), HashKey("1"))
+	s.NotEqual(HashKey(1), HashKey(2))
+	type key struct{ a, b int }
+	s.Equal(HashKey(key{1, 2}), HashKey(key{1, 2}))
+	s.NotEqual(HashKey(key{1, 2}), HashKey(key{2, 1}))
+}
+
+// TestPolicies - admitter works in front of every Store policy
+func (s *suiteAdmission) TestPolicies() {
+	policies := map[string]func() Policy[string]{
+		"lru":  func() Policy[string] { return NewLRUPolicy[string]() },
+		"s2q":  func() Policy[string] { return NewSimplified2QPolicy[string](75, 25) },
+		"2q":   func() Policy[string] { return NewFull2QPolicy[string](75, 25, 50) },
+		"mq":   func() Policy[string] { return NewMQPolicy[string](8, 100, 1000, nil) },
+		"lfu":  func() Policy[string] { return NewLFUPolicy[string](LFUNoAging, 0) },
+		"lfua": func() Policy[string] { return NewLFUPolicy[string](LFUDynamicAging, 0) },
+	}
+	for name, policy := range policies {
+		admitted := hitRatioWithScans(NewStore[string, int](policy(), 100, nil).
+			WithAdmitter(NewFrequencyAdmitter[string](100, nil)))
+		s.Greater(admitted, 0.6, name)
+	}
+	lru := hitRatioWithScans(NewLRU[string, int](100, nil))
+	s.Less(lru, 0.6)
+}
+
+// hitRatioWithScans - read-through cache, hot keys mixed with a scan of unique keys
+func hitRatioWithScans(c Cache[string, int]) float64 {
+	rnd := rand.New(rand.NewSource(1))
+	hits, unique := 0, 0
+	const requests = 50000
+	for i := 0; i < requests; i++ {
+		var k string
+		if rnd.Intn(3) == 0 {
+			unique += 1
+			k = "u" + strconv.Itoa(unique)
+		} else {
+			k = strconv.Itoa(rnd.Intn(80))
+		}
+		if _, ok := c.Get(k, 0); ok {
+			hits += 1
+		} else {
+			c.Put(k, i)
+		}
+	}
+	return float64(hits) / requests
+}
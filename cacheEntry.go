@@ -1,23 +1,136 @@
 package allcache
 
+import "github.com/satmaelstorm/list"
+
 type cacheEntry[K comparable, T any] struct {
 	key   K
 	value T
 }
 
-type cacheEntry2Q[K comparable, T any] struct {
-	cacheEntry[K, T]
+type cacheEntry2Q[K comparable] struct {
+	key  K
 	isAm bool
 }
 
-type cacheEntryMQ[K comparable, T any] struct {
-	cacheEntry[K, T]
+type cacheEntryMQ[K comparable] struct {
+	key    K
 	qNum   byte
 	hits   uint64
 	expire uint64
+	// last - logical time of the last access
+	last uint64
 }
 
 type cacheEntryOutMQ[K comparable] struct {
 	key  K
 	hits uint64
+	last uint64
+}
+
+type lirsState byte
+
+const (
+	lirsLIR lirsState = iota
+	lirsHIR
+	lirsGhost
+)
+
+type cacheEntryLIRS[K comparable, T any] struct {
+	cacheEntry[K, T]
+	size  uint64
+	state lirsState
+	// s - node in stack S, nil if entry is not in S
+	s *list.Node[K]
+	// q - node in queue of resident HIR entries or in queue of ghosts
+	q *list.Node[K]
+}
+
+type cacheEntryClock[K comparable, T any] struct {
+	cacheEntry[K, T]
+	size uint64
+	// ref - reference bit, set atomically by readers
+	ref uint32
+}
+
+type clockProKind byte
+
+const (
+	clockProCold clockProKind = iota
+	clockProHot
+	clockProTest
+)
+
+// cacheEntryClockPro - entry is the node of CLOCK-Pro circular list
+type cacheEntryClockPro[K comparable, T any] struct {
+	cacheEntry[K, T]
+	kind clockProKind
+	// inTest - resident cold entry is in its test period, test entries are always in it
+	inTest bool
+	// ref - reference bit, set atomically by readers
+	ref        uint32
+	next, prev *cacheEntryClockPro[K, T]
+}
+
+type cacheEntryS3FIFO[K comparable, T any] struct {
+	cacheEntry[K, T]
+	size uint64
+	// freq - 2-bit access counter, increased atomically by readers
+	freq   uint32
+	isMain bool
+}
+
+// cacheEntryOut2Q - key in A1out of full 2Q, seq is the number of the key in order of eviction from A1in
+type cacheEntryOut2Q[K comparable] struct {
+	key K
+	seq uint64
+}
+
+type cacheEntryGhost[K comparable] struct {
+	key  K
+	size uint64
+}
+
+type cacheEntryGDSF[K comparable, T any] struct {
+	cacheEntry[K, T]
+	size uint64
+	cost uint64
+	freq uint64
+}
+
+type cacheEntryLRUK[K comparable, T any] struct {
+	cacheEntry[K, T]
+	size uint64
+	// hist - times of the last K uncorrelated references, the most recent first, 0 if there was no reference
+	hist []uint64
+	// last - time of the last reference, correlated or not
+	last uint64
+	// young - node in queue of entries with less than K references
+	young *list.Node[*cacheEntryLRUK[K, T]]
+	// old - item in heap of entries with K references
+	old *heapItem[uint64, *cacheEntryLRUK[K, T]]
+}
+
+type cacheEntryHistory[K comparable] struct {
+	key  K
+	hist []uint64
+}
+
+type cacheEntryLFU[K comparable] struct {
+	key  K
+	freq uint64
+}
+
+type cacheEntrySLRU[K comparable, T any] struct {
+	cacheEntry[K, T]
+	size        uint64
+	isProtected bool
+}
+
+type cacheEntrySampled[K comparable, T any] struct {
+	cacheEntry[K, T]
+	size uint64
+	freq uint64
+	last uint64
+	// pos - index in dense slice of entries
+	pos int
 }
@@ -0,0 +1,140 @@
+package allcache
+
+import (
+	"github.com/satmaelstorm/list"
+	"sync"
+	"sync/atomic"
+)
+
+// Clock - CLOCK (second chance) eviction policy.
+// Hit only sets reference bit of the entry, so Get needs read lock only.
+type Clock[K comparable, T any] struct {
+	cache *ntsClock[K, T]
+	lock  sync.RWMutex
+}
+
+// NewClock - items larger than maxSize are not cached
+func NewClock[K comparable, T any](maxSize uint64, calcSize SizeCalculator[T]) Cache[K, T] {
+	cache := new(Clock[K, T])
+	cache.cache = newNtsClock[K, T](maxSize, calcSize)
+	return cache
+}
+
+func (c *Clock[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *Clock[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.cache.get(key, def)
+}
+
+func (c *Clock[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *Clock[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe CLOCK, except get, which is safe with other get.
+// Clock is a queue, the hand points to its head.
+type ntsClock[K comparable, T any] struct {
+	items    map[K]*list.Node[*cacheEntryClock[K, T]]
+	clock    *list.Queue[*cacheEntryClock[K, T]]
+	length   uint64
+	maxSize  uint64
+	sizeCalc SizeCalculator[T]
+}
+
+func newNtsClock[K comparable, T any](maxSize uint64, sizeCalc SizeCalculator[T]) *ntsClock[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	return &ntsClock[K, T]{
+		items:    make(map[K]*list.Node[*cacheEntryClock[K, T]], maxSize),
+		clock:    list.NewQueue[*cacheEntryClock[K, T]](),
+		maxSize:  maxSize,
+		sizeCalc: sizeCalc,
+	}
+}
+
+func (c *ntsClock[K, T]) get(key K, def T) (T, bool) {
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		atomic.StoreUint32(&entry.ref, 1)
+		return entry.value, true
+	}
+	return def, false
+}
+
+func (c *ntsClock[K, T]) put(key K, value T) {
+	size := c.sizeCalc(value)
+	if size > c.maxSize {
+		c.delete(key)
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		c.length += size - entry.size
+		entry.value = value
+		entry.size = size
+		atomic.StoreUint32(&entry.ref, 1)
+		c.reclaim(0)
+		return
+	}
+	c.reclaim(size)
+	entry := &cacheEntryClock[K, T]{size: size}
+	entry.key = key
+	entry.value = value
+	c.clock.Enqueue(entry)
+	c.items[key] = c.clock.Tail()
+	c.length += size
+}
+
+// reclaim - sweep the hand: referenced entries lose the bit and get second chance, others are evicted
+func (c *ntsClock[K, T]) reclaim(size uint64) {
+	for c.length+size > c.maxSize {
+		h := c.clock.Head()
+		if nil == h {
+			return
+		}
+		entry := h.Value()
+		if atomic.LoadUint32(&entry.ref) != 0 {
+			atomic.StoreUint32(&entry.ref, 0)
+			c.clock.MoveToBack(h)
+			continue
+		}
+		c.clock.Dequeue()
+		delete(c.items, entry.key)
+		c.length -= entry.size
+	}
+}
+
+func (c *ntsClock[K, T]) delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.clock.Remove(e)
+		delete(c.items, key)
+		c.length -= e.Value().size
+	}
+}
+
+func (c *ntsClock[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.Value().value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
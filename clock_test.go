@@ -0,0 +1,113 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type suiteNtsClock struct {
+	suite.Suite
+	cache *ntsClock[string, int]
+}
+
+func TestNtsClock(t *testing.T) {
+	suite.Run(t, new(suiteNtsClock))
+}
+
+func (s *suiteNtsClock) SetupTest() {
+	s.cache = newNtsClock[string, int](3, nil)
+	s.cache.put("1", 1)
+	s.cache.put("2", 2)
+	s.cache.put("3", 3)
+}
+
+func (s *suiteNtsClock) TestSecondChance() {
+	r, ok := s.cache.get("1", 0)
+	s.True(ok)
+	s.Equal(1, r)
+	s.Equal("3", s.cache.clock.Tail().Value().key)
+
+	s.cache.put("4", 4)
+
+	_, ok = s.cache.get("2", 0)
+	s.False(ok)
+	for _, k := range []string{"1", "3", "4"} {
+		_, ok = s.cache.get(k, 0)
+		s.True(ok, k)
+	}
+	//1 got second chance and was moved behind 3
+	s.Equal("3", s.cache.clock.Head().Value().key)
+	s.Equal(uint64(3), s.cache.length)
+}
+
+func (s *suiteNtsClock) TestPutAndDelete() {
+	s.cache.put("2", 20)
+	r, ok := s.cache.get("2", 0)
+	s.True(ok)
+	s.Equal(20, r)
+
+	s.cache.delete("2")
+	_, ok = s.cache.get("2", 0)
+	s.False(ok)
+	s.Equal(2, s.cache.clock.Len())
+	s.Equal(uint64(2), s.cache.length)
+}
+
+func (s *suiteNtsClock) TestSizeCalculator() {
+	c := newNtsClock[string, int](10, func(v int) uint64 { return uint64(v) })
+	c.put("a", 4)
+	c.put("b", 4)
+	c.put("big", 11)
+	_, ok := c.get("big", 0)
+	s.False(ok)
+
+	c.get("a", 0)
+	c.put("c", 5)
+	_, ok = c.get("b", 0)
+	s.False(ok)
+	_, ok = c.get("a", 0)
+	s.True(ok)
+	s.Equal(uint64(9), c.length)
+
+	c.put("a", 6)
+	s.LessOrEqual(c.length, uint64(10))
+	r, ok := c.get("a", 0)
+	s.True(ok)
+	s.Equal(6, r)
+}
+
+func (s *suiteNtsClock) TestTSVersion() {
+	c := NewClock[int, int](3, nil)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
+
+func (s *suiteNtsClock) TestConcurrentReads() {
+	for _, c := range []Cache[string, int]{NewClock[string, int](50, nil), NewClockPro[string, int](50)} {
+		wg := sync.WaitGroup{}
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					k := strconv.Itoa((i * (g + 1)) % 100)
+					if _, ok := c.Get(k, 0); !ok {
+						c.Put(k, i)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+	}
+}
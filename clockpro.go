@@ -0,0 +1,310 @@
+package allcache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// clockProInitialColdPercent - initial part of memory for resident cold entries
+const clockProInitialColdPercent = 1
+
+// ClockPro - CLOCK-Pro eviction policy with hot, cold and test (non-resident cold) entries
+// and adaptive number of cold entries @see https://www.usenix.org/legacy/event/usenix05/tech/general/full_papers/jiang/jiang.pdf
+// Hit only sets reference bit of the entry, so Get needs read lock only.
+type ClockPro[K comparable, T any] struct {
+	cache *ntsClockPro[K, T]
+	lock  sync.RWMutex
+}
+
+func NewClockPro[K comparable, T any](maxSize uint64) Cache[K, T] {
+	cache := new(ClockPro[K, T])
+	cache.cache = newNtsClockPro[K, T](maxSize)
+	return cache
+}
+
+func (c *ClockPro[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *ClockPro[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.cache.get(key, def)
+}
+
+func (c *ClockPro[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *ClockPro[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe CLOCK-Pro, except get, which is safe with other get.
+// All entries are in one circular list swept by three hands, new and promoted entries are inserted
+// right behind the hot hand, i.e. at the head of the clock.
+type ntsClockPro[K comparable, T any] struct {
+	items map[K]*cacheEntryClockPro[K, T]
+
+	handHot  *cacheEntryClockPro[K, T]
+	handCold *cacheEntryClockPro[K, T]
+	handTest *cacheEntryClockPro[K, T]
+
+	maxSize uint64
+	// coldSize - adaptive target number of resident cold entries, it is in [1, maxSize]
+	coldSize uint64
+
+	countHot  uint64
+	countCold uint64
+	countTest uint64
+}
+
+func newNtsClockPro[K comparable, T any](maxSize uint64) *ntsClockPro[K, T] {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	//cold entries start with a small part of memory and get more by hits in their test periods
+	coldSize := maxSize * clockProInitialColdPercent / 100
+	if coldSize < 1 {
+		coldSize = 1
+	}
+	return &ntsClockPro[K, T]{
+		items:    make(map[K]*cacheEntryClockPro[K, T], 2*maxSize),
+		maxSize:  maxSize,
+		coldSize: coldSize,
+	}
+}
+
+func (c *ntsClockPro[K, T]) get(key K, def T) (T, bool) {
+	if e, ok := c.items[key]; ok && e.kind != clockProTest {
+		atomic.StoreUint32(&e.ref, 1)
+		return e.value, true
+	}
+	return def, false
+}
+
+func (c *ntsClockPro[K, T]) put(key K, value T) {
+	e, ok := c.items[key]
+	if ok && e.kind != clockProTest {
+		e.value = value
+		atomic.StoreUint32(&e.ref, 1)
+		return
+	}
+	if ok {
+		//hit in test period: cold entries need more space
+		c.unlink(e)
+		c.countTest -= 1
+		c.growCold()
+	}
+	c.reclaim()
+	if ok {
+		e.kind = clockProHot
+		atomic.StoreUint32(&e.ref, 0)
+		c.countHot += 1
+	} else {
+		e = &cacheEntryClockPro[K, T]{kind: clockProCold, inTest: true}
+		e.key = key
+		c.countCold += 1
+	}
+	e.value = value
+	c.items[key] = e
+	c.insertHead(e)
+	c.balanceHot()
+}
+
+func (c *ntsClockPro[K, T]) delete(key K) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.unlink(e)
+	switch e.kind {
+	case clockProHot:
+		c.countHot -= 1
+	case clockProCold:
+		c.countCold -= 1
+	case clockProTest:
+		c.countTest -= 1
+	}
+}
+
+func (c *ntsClockPro[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if clockProTest == e.kind {
+			continue
+		}
+		if f(k, e.value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+// reclaim - run cold hand until there is space for new resident entry
+func (c *ntsClockPro[K, T]) reclaim() {
+	for c.countHot+c.countCold >= c.maxSize {
+		c.runHandCold()
+	}
+}
+
+// balanceHot - run hot hand until hot entries fit into memory left by cold ones
+func (c *ntsClockPro[K, T]) balanceHot() {
+	for c.countHot > 0 && c.countHot > c.maxSize-c.coldSize {
+		c.runHandHot()
+	}
+}
+
+func (c *ntsClockPro[K, T]) growCold() {
+	if c.coldSize < c.maxSize {
+		c.coldSize += 1
+	}
+}
+
+func (c *ntsClockPro[K, T]) shrinkCold() {
+	if c.coldSize > 1 {
+		c.coldSize -= 1
+	}
+}
+
+// insertHead - insert entry right behind the hot hand, the entry must be in items already
+func (c *ntsClockPro[K, T]) insertHead(e *cacheEntryClockPro[K, T]) {
+	if nil == c.handHot {
+		e.next, e.prev = e, e
+		c.handHot, c.handCold, c.handTest = e, e, e
+		return
+	}
+	e.next = c.handHot
+	e.prev = c.handHot.prev
+	e.prev.next = e
+	c.handHot.prev = e
+}
+
+// moveToHead - move entry of the clock to its head
+func (c *ntsClockPro[K, T]) moveToHead(e *cacheEntryClockPro[K, T]) {
+	if e.next == e {
+		return
+	}
+	c.detach(e)
+	c.insertHead(e)
+}
+
+// unlink - remove entry from the clock and items
+func (c *ntsClockPro[K, T]) unlink(e *cacheEntryClockPro[K, T]) {
+	delete(c.items, e.key)
+	if e.next == e {
+		c.handHot, c.handCold, c.handTest = nil, nil, nil
+		e.next, e.prev = nil, nil
+		return
+	}
+	c.detach(e)
+}
+
+// detach - remove entry from the clock, hands pointing to it move to the next entry.
+// The entry must not be the only one.
+func (c *ntsClockPro[K, T]) detach(e *cacheEntryClockPro[K, T]) {
+	if e == c.handHot {
+		c.handHot = e.next
+	}
+	if e == c.handCold {
+		c.handCold = e.next
+	}
+	if e == c.handTest {
+		c.handTest = e.next
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.next, e.prev = nil, nil
+}
+
+// runHandCold - move cold hand to the first not referenced resident cold entry and evict it:
+// it stays in the clock as test entry if it is in its test period. Referenced cold entry in its test period
+// becomes hot, referenced cold entry out of test period starts new test period, both go to the head.
+func (c *ntsClockPro[K, T]) runHandCold() {
+	for {
+		if 0 == c.countCold {
+			//hot entries take all memory, e.g. after deletes of cold ones
+			c.runHandHot()
+			continue
+		}
+		e := c.handCold
+		c.handCold = e.next
+		if e.kind != clockProCold {
+			continue
+		}
+		if atomic.SwapUint32(&e.ref, 0) != 0 {
+			if e.inTest {
+				e.kind = clockProHot
+				e.inTest = false
+				c.countCold -= 1
+				c.countHot += 1
+				c.moveToHead(e)
+				c.balanceHot()
+			} else {
+				e.inTest = true
+				c.moveToHead(e)
+			}
+			continue
+		}
+		c.countCold -= 1
+		if !e.inTest {
+			c.unlink(e)
+			return
+		}
+		var zero T
+		e.kind = clockProTest
+		e.value = zero
+		c.countTest += 1
+		for c.countTest > c.maxSize {
+			c.runHandTest()
+		}
+		return
+	}
+}
+
+// runHandHot - move hot hand to the first not referenced hot entry and turn it cold,
+// the hot hand pushes the test hand, so test periods end before hot hand passes the entry.
+func (c *ntsClockPro[K, T]) runHandHot() {
+	for {
+		if c.handHot == c.handTest {
+			c.runHandTest()
+		}
+		e := c.handHot
+		c.handHot = e.next
+		if e.kind != clockProHot {
+			continue
+		}
+		if atomic.SwapUint32(&e.ref, 0) != 0 {
+			continue
+		}
+		e.kind = clockProCold
+		c.countHot -= 1
+		c.countCold += 1
+		return
+	}
+}
+
+// runHandTest - end test period of the cold entry under test hand: test entry leaves the clock,
+// resident cold entry stays cold. Test period without hit means cold entries need less space.
+func (c *ntsClockPro[K, T]) runHandTest() {
+	e := c.handTest
+	c.handTest = e.next
+	switch {
+	case clockProTest == e.kind:
+		c.unlink(e)
+		c.countTest -= 1
+		c.shrinkCold()
+	case clockProCold == e.kind && e.inTest:
+		e.inTest = false
+		c.shrinkCold()
+	}
+}
@@ -0,0 +1,222 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsClockPro struct {
+	suite.Suite
+	cache *ntsClockPro[string, int]
+}
+
+func TestNtsClockPro(t *testing.T) {
+	suite.Run(t, new(suiteNtsClockPro))
+}
+
+func (s *suiteNtsClockPro) SetupTest() {
+	s.cache = newNtsClockPro[string, int](3)
+	s.cache.put("1", 1)
+	s.cache.put("2", 2)
+	s.cache.put("3", 3)
+}
+
+// checkClockPro - counters match entries of the clock
+func checkClockPro[K comparable](s *suiteNtsClockPro, c *ntsClockPro[K, int]) {
+	counts := map[clockProKind]uint64{}
+	n := 0
+	if c.handHot != nil {
+		e := c.handHot
+		for {
+			counts[e.kind] += 1
+			n += 1
+			s.Equal(e, e.next.prev)
+			e = e.next
+			if e == c.handHot {
+				break
+			}
+		}
+	}
+	s.Equal(len(c.items), n)
+	s.Equal(c.countHot, counts[clockProHot])
+	s.Equal(c.countCold, counts[clockProCold])
+	s.Equal(c.countTest, counts[clockProTest])
+	s.LessOrEqual(c.countHot+c.countCold, c.maxSize)
+	s.LessOrEqual(c.countTest, c.maxSize)
+}
+
+func (s *suiteNtsClockPro) TestFill() {
+	checkClockPro(s, s.cache)
+	s.Equal(uint64(3), s.cache.countCold)
+	for i := 1; i <= 3; i++ {
+		r, ok := s.cache.get(strconv.Itoa(i), 0)
+		s.True(ok)
+		s.Equal(i, r)
+	}
+}
+
+func (s *suiteNtsClockPro) TestTestPeriod() {
+	s.cache.put("4", 4)
+	checkClockPro(s, s.cache)
+	s.Equal(uint64(1), s.cache.countTest)
+
+	var test string
+	for k, e := range s.cache.items {
+		if clockProTest == e.kind {
+			test = k
+		}
+	}
+	_, ok := s.cache.get(test, 0)
+	s.False(ok)
+
+	coldSize := s.cache.coldSize
+	s.cache.put(test, 10)
+	checkClockPro(s, s.cache)
+	s.Equal(clockProHot, s.cache.items[test].kind)
+	s.Equal(coldSize+1, s.cache.coldSize)
+	r, ok := s.cache.get(test, 0)
+	s.True(ok)
+	s.Equal(10, r)
+}
+
+func (s *suiteNtsClockPro) TestColdHand() {
+	//referenced cold entry in its test period becomes hot
+	s.cache.get("1", 0)
+	s.cache.put("4", 4)
+	checkClockPro(s, s.cache)
+	s.Equal(clockProHot, s.cache.items["1"].kind)
+	s.Equal(clockProTest, s.cache.items["2"].kind)
+
+	//cold entry out of test period is evicted without test entry
+	e := s.cache.items["3"]
+	e.inTest = false
+	s.cache.put("5", 5)
+	checkClockPro(s, s.cache)
+	s.NotContains(s.cache.items, "3")
+	s.Equal(uint64(1), s.cache.countTest)
+
+	//referenced cold entry out of test period starts new one and stays cold
+	e = s.cache.items["4"]
+	e.inTest = false
+	s.cache.get("4", 0)
+	s.cache.put("6", 6)
+	checkClockPro(s, s.cache)
+	s.Equal(clockProCold, e.kind)
+	s.True(e.inTest)
+}
+
+func (s *suiteNtsClockPro) TestDelete() {
+	s.cache.put("4", 4)
+	s.cache.put("5", 5)
+	for _, k := range []string{"1", "2", "3", "4", "5"} {
+		s.cache.delete(k)
+		checkClockPro(s, s.cache)
+	}
+	s.Empty(s.cache.items)
+	s.Nil(s.cache.handHot)
+
+	s.cache.put("6", 6)
+	checkClockPro(s, s.cache)
+	r, ok := s.cache.get("6", 0)
+	s.True(ok)
+	s.Equal(6, r)
+}
+
+func (s *suiteNtsClockPro) TestLoop() {
+	c := newNtsClockPro[int, int](10)
+	lru := newNtsStore[int, int](newNtsLRU[int](), 10, nil)
+	hits, lruHits := 0, 0
+	for i := 0; i < 2000; i++ {
+		k := i % 12
+		if _, ok := c.get(k, 0); ok {
+			hits += 1
+		} else {
+			c.put(k, k)
+		}
+		if _, ok := lru.get(k, 0); ok {
+			lruHits += 1
+		} else {
+			lru.put(k, k)
+		}
+	}
+	s.Equal(0, lruHits)
+	s.Greater(hits, 500)
+	//hits in test periods gave space to cold entries
+	s.Greater(c.coldSize, uint64(1))
+	checkClockPro(s, c)
+}
+
+func (s *suiteNtsClockPro) TestScan() {
+	c := newNtsClockPro[int, int](100)
+	lru := newNtsStore[int, int](newNtsLRU[int](), 100, nil)
+	access := func(k int) (bool, bool) {
+		_, ok := c.get(k, 0)
+		if !ok {
+			c.put(k, k)
+		}
+		_, lruOk := lru.get(k, 0)
+		if !lruOk {
+			lru.put(k, k)
+		}
+		return ok, lruOk
+	}
+	for i := 0; i < 300; i++ {
+		access(i % 60)
+	}
+	//scans of new keys larger than cache between passes over 60 frequent keys
+	hits, lruHits := 0, 0
+	scan := 1000
+	for r := 0; r < 20; r++ {
+		for i := 0; i < 200; i++ {
+			access(scan)
+			scan += 1
+		}
+		for i := 0; i < 60; i++ {
+			ok, lruOk := access(i)
+			if ok {
+				hits += 1
+			}
+			if lruOk {
+				lruHits += 1
+			}
+		}
+	}
+	s.Equal(0, lruHits)
+	s.Equal(20*60, hits)
+	s.Equal(uint64(60), c.countHot)
+	checkClockPro(s, c)
+}
+
+func (s *suiteNtsClockPro) TestRandom() {
+	c := newNtsClockPro[string, int](20)
+	for i := 0; i < 5000; i++ {
+		k := strconv.Itoa((i * 7919) % 97 % (i%31 + 1))
+		switch i % 7 {
+		case 0:
+			c.delete(k)
+		case 1, 2:
+			c.put(k, i)
+		default:
+			if _, ok := c.get(k, 0); !ok {
+				c.put(k, i)
+			}
+		}
+	}
+	checkClockPro(s, c)
+}
+
+func (s *suiteNtsClockPro) TestTSVersion() {
+	c := NewClockPro[int, int](3)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
@@ -0,0 +1,163 @@
+// allcache-sim replays an access trace against allcache policies as a read-through cache
+// and reports hit ratio, byte hit ratio, evictions and throughput per policy and capacity.
+//
+// Trace formats are those of the trace package, gzip compressed traces are supported.
+// With -mrc miss ratio curves over capacities are written as CSV instead: exact for lru,
+// approximated with SHARDS sampling for other policies.
+// With -opt Belady's optimal offline policy is replayed too and gap of every policy from it is reported.
+//
+//	allcache-sim -trace arc -policies lru,2q,mq -capacities 1000,10000 -format csv P1.lis.gz
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/satmaelstorm/allcache/mrc"
+	"github.com/satmaelstorm/allcache/sim"
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "allcache-sim:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("allcache-sim", flag.ContinueOnError)
+	policiesFlag := fs.String("policies", "lru,lru2,slru,s2q,2q,2qa,mq,mqa,lirs,clock,clockpro,s3fifo,sieve,gdsf,random,sampledlru,sampledlfu,lfu,lfuda", "comma separated policies: "+policiesList())
+	capacitiesFlag := fs.String("capacities", "1000", "comma separated cache capacities")
+	format := fs.String("format", "table", "output format: table or csv")
+	traceFormat := fs.String("trace", string(trace.FormatLIRS), "trace format: "+formatsList())
+	weighted := fs.Bool("weighted", false, "measure capacity in bytes using object sizes (all policies except s2q, 2q, 2qa, clockpro, lfu and lfuda)")
+	curves := fs.Bool("mrc", false, "write miss ratio curves over capacities as CSV")
+	rate := fs.Float64("sample", 0.01, "SHARDS sampling rate of miss ratio curves")
+	withOpt := fs.Bool("opt", false, "replay Belady OPT too and report gap of every policy from it")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: allcache-sim [flags] [trace file, stdin if omitted]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	policies, err := parsePolicies(*policiesFlag)
+	if err != nil {
+		return err
+	}
+	capacities, err := parseCapacities(*capacitiesFlag)
+	if err != nil {
+		return err
+	}
+
+	var sampler *mrc.Sampler
+	if *curves {
+		if sampler, err = mrc.NewSampler(*rate); err != nil {
+			return err
+		}
+	}
+
+	var write func(io.Writer, []sim.Result) error
+	switch *format {
+	case "table":
+		write = sim.WriteTable
+	case "csv":
+		write = sim.WriteCSV
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	var tr trace.Reader
+	if fs.NArg() > 0 {
+		f, err := trace.Open(fs.Arg(0), trace.Format(*traceFormat))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		tr = f
+	} else if tr, err = trace.NewReader(stdin, trace.Format(*traceFormat)); err != nil {
+		return err
+	}
+
+	if *curves {
+		result, err := missRatioCurves(tr, policies, capacities, sampler, *weighted)
+		if err != nil {
+			return err
+		}
+		return mrc.WriteCSV(stdout, result...)
+	}
+
+	requests, err := trace.ReadAll(tr)
+	if err != nil {
+		return err
+	}
+
+	results := make([]sim.Result, 0, (len(policies)+1)*len(capacities))
+	if *withOpt {
+		for _, capacity := range capacities {
+			results = append(results, sim.OPT(requests, capacity, *weighted))
+		}
+	}
+	for _, p := range policies {
+		for _, capacity := range capacities {
+			r, err := sim.Replay(requests, p, capacity, *weighted)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.Name, err)
+			}
+			results = append(results, r)
+		}
+	}
+	if *withOpt {
+		sim.SetGaps(results, results[:len(capacities)])
+	}
+	return write(stdout, results)
+}
+
+func policiesList() string {
+	var names []string
+	for _, p := range sim.Policies() {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func formatsList() string {
+	var names []string
+	for _, f := range trace.Formats() {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
+
+func parsePolicies(s string) ([]sim.Policy, error) {
+	var result []sim.Policy
+	for _, name := range strings.Split(s, ",") {
+		p, err := sim.PolicyByName(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func parseCapacities(s string) ([]uint64, error) {
+	var result []uint64
+	for _, c := range strings.Split(s, ",") {
+		capacity, err := strconv.ParseUint(strings.TrimSpace(c), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad capacity %q: %w", c, err)
+		}
+		if 0 == capacity {
+			return nil, fmt.Errorf("capacity must be positive")
+		}
+		result = append(result, capacity)
+	}
+	return result, nil
+}
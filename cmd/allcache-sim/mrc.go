@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/satmaelstorm/allcache/mrc"
+	"github.com/satmaelstorm/allcache/sim"
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+// missRatioCurves - read trace once, LRU curve is exact if capacity is in items,
+// other curves are built from sampled requests
+func missRatioCurves(
+	tr trace.Reader,
+	policies []sim.Policy,
+	capacities []uint64,
+	sampler *mrc.Sampler,
+	weighted bool,
+) ([]mrc.Curve, error) {
+	var lru *mrc.LRU
+	for _, p := range policies {
+		if "lru" == p.Name && !weighted {
+			lru = mrc.NewLRU()
+		}
+	}
+
+	var sampled []trace.Request
+	for {
+		req, err := tr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if lru != nil {
+			lru.Access(req)
+		}
+		if sampler.Observe(req) {
+			sampled = append(sampled, req)
+		}
+	}
+
+	result := make([]mrc.Curve, 0, len(policies))
+	for _, p := range policies {
+		if "lru" == p.Name && lru != nil {
+			result = append(result, lru.Curve(capacities))
+			continue
+		}
+		c, err := mrc.Sampled(sampled, sampler, p, capacities, weighted)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Name, err)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
@@ -0,0 +1,83 @@
+// allcache-tune searches parameters of Full2Q and MQ caches with the best hit ratio on a trace
+// and prints recommended constructor arguments.
+//
+//	allcache-tune -trace arc -capacity 10000 -policy 2q,mq -strategy hill P1.lis.gz
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/satmaelstorm/allcache/trace"
+	"github.com/satmaelstorm/allcache/tune"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "allcache-tune:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("allcache-tune", flag.ContinueOnError)
+	policiesFlag := fs.String("policy", "2q,mq", "comma separated policies to tune: 2q, mq")
+	capacity := fs.Uint64("capacity", 1000, "total cache capacity in items")
+	strategyFlag := fs.String("strategy", "hill", "search strategy: grid or hill")
+	traceFormat := fs.String("trace", string(trace.FormatLIRS), "trace format")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: allcache-tune [flags] [trace file, stdin if omitted]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	strategy, err := tune.ParseStrategy(*strategyFlag)
+	if err != nil {
+		return err
+	}
+
+	var tr trace.Reader
+	if fs.NArg() > 0 {
+		f, err := trace.Open(fs.Arg(0), trace.Format(*traceFormat))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		tr = f
+	} else if tr, err = trace.NewReader(stdin, trace.Format(*traceFormat)); err != nil {
+		return err
+	}
+	requests, err := trace.ReadAll(tr)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range strings.Split(*policiesFlag, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "2q":
+			r, err := tune.Full2Q(requests, *capacity, strategy)
+			if err != nil {
+				return err
+			}
+			report(stdout, r.Params, r.HitRatio, r.Default, r.DefaultHitRatio, r.Evaluated)
+		case "mq":
+			r, err := tune.MQ(requests, *capacity, strategy)
+			if err != nil {
+				return err
+			}
+			report(stdout, r.Params, r.HitRatio, r.Default, r.DefaultHitRatio, r.Evaluated)
+		default:
+			return fmt.Errorf("unknown policy %q", name)
+		}
+	}
+	return nil
+}
+
+func report(w io.Writer, best fmt.Stringer, hitRatio float64, def fmt.Stringer, defHitRatio float64, evaluated int) {
+	fmt.Fprintf(w, "%s\n\thit ratio %.4f (default %s: %.4f), %d configurations evaluated\n",
+		best, hitRatio, def, defHitRatio, evaluated)
+}
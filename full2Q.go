@@ -1,151 +1,299 @@
 package allcache
 
-import (
-	"github.com/satmaelstorm/list"
-	"sync"
+import "github.com/satmaelstorm/list"
+
+const (
+	// full2QInPercent, full2QOutPercent - Kin and Kout recommended by the 2Q paper, start of adaptive mode
+	full2QInPercent  = 25
+	full2QOutPercent = 50
+	// full2QStepPercent - Kin and Kout are changed by this part of capacity after every window
+	full2QStepPercent = 5
+	// full2QMinInPercent, full2QMaxInPercent - bounds of Kin in adaptive mode
+	full2QMinInPercent = 5
+	full2QMaxInPercent = 75
+	// full2QMinOutPercent, full2QMaxOutPercent - bounds of Kout in adaptive mode
+	full2QMinOutPercent = 10
+	full2QMaxOutPercent = 200
 )
 
 // Full2Q - full version 2Q - @see http://www.vldb.org/conf/1994/P439.PDF
 type Full2Q[K comparable, T any] struct {
-	cache *ntsFull2Q[K, T]
-	lock  sync.Mutex
+	*Store[K, T]
+	policy *ntsFull2Q[K]
+}
+
+// Full2QSplit - current sizes of queues of Full2Q
+type Full2QSplit struct {
+	AmSize    uint64
+	A1InSize  uint64
+	A1OutSize uint64
 }
 
 func NewFull2Q[K comparable, T any](amSize, a1InSize, a1OutSize uint64) Cache[K, T] {
-	cache := new(Full2Q[K, T])
-	cache.cache = newNtsFull2Q[K, T](amSize, a1InSize, a1OutSize)
-	return cache
+	policy := newNtsFull2Q[K](amSize, a1InSize, a1OutSize)
+	return &Full2Q[K, T]{Store: NewStore[K, T](policy, amSize+a1InSize, nil), policy: policy}
 }
 
-func (c *Full2Q[K, T]) Put(key K, item T) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.cache.put(key, item)
+// NewAdaptiveFull2Q - Full2Q which starts with Kin 25% and Kout 50% of capacity and tunes them:
+// every window of capacity requests A1in grows if ghost hits in A1out per ghost exceed hits in Am per Am slot
+// and shrinks otherwise. A1out remembers up to 4 capacities of keys to see hits behind its end,
+// it grows if they get a quarter of ghost hits and shrinks if neither they nor its older half get any.
+// Hits are counted in sliding windows: counters are halved, not reset, after every window.
+func NewAdaptiveFull2Q[K comparable, T any](capacity uint64) *Full2Q[K, T] {
+	policy := newAdaptiveNtsFull2Q[K](capacity)
+	return &Full2Q[K, T]{Store: NewStore[K, T](policy, capacity, nil), policy: policy}
 }
 
-func (c *Full2Q[K, T]) Get(key K, def T) (T, bool) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.cache.get(key, def)
+// NewFull2QPolicy - full 2Q policy for Store with capacity amSize+a1InSize, weights are ignored
+func NewFull2QPolicy[K comparable](amSize, a1InSize, a1OutSize uint64) Policy[K] {
+	return newNtsFull2Q[K](amSize, a1InSize, a1OutSize)
 }
 
-func (c *Full2Q[K, T]) Delete(key K) {
+// NewAdaptiveFull2QPolicy - adaptive full 2Q policy for Store with given capacity, weights are ignored
+func NewAdaptiveFull2QPolicy[K comparable](capacity uint64) Policy[K] {
+	return newAdaptiveNtsFull2Q[K](capacity)
+}
+
+// Split - current sizes of Am, A1in and A1out, they change in adaptive mode only
+func (c *Full2Q[K, T]) Split() Full2QSplit {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	c.cache.delete(key)
+	return Full2QSplit{AmSize: c.policy.amSize, A1InSize: c.policy.a1InSize, A1OutSize: c.policy.a1OutSize}
 }
 
-//non thead safe full version 2Q - @see http://www.vldb.org/conf/1994/P439.PDF
-type ntsFull2Q[K comparable, T any] struct {
-	items map[K]*list.Node[cacheEntry2Q[K, T]]
-	am    *list.Queue[cacheEntry2Q[K, T]]
-	a1in  *list.Queue[cacheEntry2Q[K, T]]
+// non thead safe full version 2Q - @see http://www.vldb.org/conf/1994/P439.PDF
+type ntsFull2Q[K comparable] struct {
+	heldKeys[K]
+	items map[K]*list.Node[cacheEntry2Q[K]]
+	am    *list.Queue[cacheEntry2Q[K]]
+	a1in  *list.Queue[cacheEntry2Q[K]]
 
-	itemsOut map[K]*list.Node[K]
-	a1out    *list.Queue[K]
+	itemsOut map[K]*list.Node[cacheEntryOut2Q[K]]
+	a1out    *list.Queue[cacheEntryOut2Q[K]]
+	// outSeq - number of keys evicted from A1in, age of a key in A1out is counted in evictions
+	outSeq uint64
+	// outLimit - length of A1out, in adaptive mode it is longer than a1OutSize
+	// to see hits which A1out would get if it were longer
+	outLimit uint64
 
 	amSize    uint64
 	a1InSize  uint64
 	a1OutSize uint64
-	totalSize uint64
+
+	// adaptive - sizes are tuned after every window of requests by hits decayed over windows
+	adaptive     bool
+	capacity     uint64
+	window       uint64
+	requests     uint64
+	amHits       uint64
+	ghostHits    uint64
+	oldGhostHits uint64
+	shadowHits   uint64
 }
 
-func newNtsFull2Q[K comparable, T any](amSize, a1InSize, a1OutSize uint64) *ntsFull2Q[K, T] {
-	return &ntsFull2Q[K, T]{
-		items: make(map[K]*list.Node[cacheEntry2Q[K, T]], amSize+a1InSize),
-		am:    list.NewQueue[cacheEntry2Q[K, T]](),
-		a1in:  list.NewQueue[cacheEntry2Q[K, T]](),
+func newNtsFull2Q[K comparable](amSize, a1InSize, a1OutSize uint64) *ntsFull2Q[K] {
+	return &ntsFull2Q[K]{
+		heldKeys: make(heldKeys[K]),
+		items:    make(map[K]*list.Node[cacheEntry2Q[K]], amSize+a1InSize),
+		am:       list.NewQueue[cacheEntry2Q[K]](),
+		a1in:     list.NewQueue[cacheEntry2Q[K]](),
+
+		itemsOut: make(map[K]*list.Node[cacheEntryOut2Q[K]], a1OutSize),
+		a1out:    list.NewQueue[cacheEntryOut2Q[K]](),
 
-		itemsOut: make(map[K]*list.Node[K], a1OutSize),
-		a1out:    list.NewQueue[K](),
+		outLimit: a1OutSize,
 
 		amSize:    amSize,
 		a1InSize:  a1InSize,
 		a1OutSize: a1OutSize,
+	}
+}
+
+func newAdaptiveNtsFull2Q[K comparable](capacity uint64) *ntsFull2Q[K] {
+	a1InSize := percentOf(capacity, full2QInPercent)
+	if a1InSize > capacity {
+		a1InSize = capacity
+	}
+	c := newNtsFull2Q[K](capacity-a1InSize, a1InSize, percentOf(capacity, full2QOutPercent))
+	c.adaptive = true
+	c.capacity = capacity
+	c.outLimit = 2 * percentOf(capacity, full2QMaxOutPercent)
+	c.window = capacity
+	if c.window < 1 {
+		c.window = 1
+	}
+	return c
+}
 
-		totalSize: amSize + a1InSize,
+// percentOf - part of capacity, but at least 1
+func percentOf(capacity, percent uint64) uint64 {
+	r := capacity * percent / 100
+	if r < 1 {
+		return 1
 	}
+	return r
 }
 
-func (c *ntsFull2Q[K, T]) get(key K, def T) (T, bool) {
-	if e, ok := c.items[key]; ok {
-		if e.Value().isAm {
-			c.am.MoveToBack(e)
+// OnInsert - key remembered in A1out goes to Am, other new key goes to A1in
+func (c *ntsFull2Q[K]) OnInsert(key K, _ uint64) {
+	if _, ok := c.items[key]; ok {
+		c.OnAccess(key)
+		return
+	}
+	defer c.request()
+	if e, ok := c.itemsOut[key]; ok {
+		c.a1out.Remove(e)
+		delete(c.itemsOut, key)
+		if c.isGhostHit(e.Value()) {
+			c.am.Enqueue(cacheEntry2Q[K]{key: key, isAm: true})
+			c.items[key] = c.am.Tail()
+			return
 		}
-		return e.Value().value, true
 	}
-	return def, false
+	c.a1in.Enqueue(cacheEntry2Q[K]{key: key})
+	c.items[key] = c.a1in.Tail()
 }
 
-func (c *ntsFull2Q[K, T]) put(key K, value T) {
-	if e, ok := c.items[key]; ok {
-		cacheEntry := e.Value()
-		cacheEntry.value = value
-		if cacheEntry.isAm {
-			e.SetValue(cacheEntry)
-			c.am.MoveToBack(e)
-		} else {
-			e.SetValue(cacheEntry)
+// OnAccess - hits in A1in are correlated references and don't move the key
+func (c *ntsFull2Q[K]) OnAccess(key K) {
+	defer c.request()
+	if e, ok := c.items[key]; ok && e.Value().isAm {
+		c.amHits += 1
+		c.am.MoveToBack(e)
+	}
+}
+
+// OnRemove - key evicted from A1in is remembered in A1out
+func (c *ntsFull2Q[K]) OnRemove(key K, reason RemoveReason) {
+	e, ok := c.items[key]
+	if !ok {
+		if g, ok := c.itemsOut[key]; ok && RemoveDeleted == reason {
+			c.a1out.Remove(g)
+			delete(c.itemsOut, key)
 		}
 		return
 	}
+	delete(c.items, key)
+	delete(c.heldKeys, key)
+	if e.Value().isAm {
+		c.am.Remove(e)
+		return
+	}
+	c.a1in.Remove(e)
+	if reason != RemoveEvicted {
+		return
+	}
+	c.outSeq += 1
+	c.a1out.Enqueue(cacheEntryOut2Q[K]{key: key, seq: c.outSeq})
+	c.itemsOut[key] = c.a1out.Tail()
+	c.trimOut()
+}
 
-	c.reclaim()
+// Victim - head of A1in if A1in is larger than its size, head of Am otherwise, held keys are skipped
+func (c *ntsFull2Q[K]) Victim() (K, bool) {
+	if uint64(c.a1in.Len()) > c.a1InSize {
+		if key, ok := c.first2Q(c.a1in); ok {
+			return key, true
+		}
+	}
+	if key, ok := c.first2Q(c.am); ok {
+		return key, true
+	}
+	return c.first2Q(c.a1in)
+}
 
-	cacheEntry := cacheEntry2Q[K, T]{isAm: false}
-	cacheEntry.key = key
-	cacheEntry.value = value
+// isGhostHit - key is in A1out of a1OutSize, in adaptive mode hits of older keys are counted as shadow hits
+func (c *ntsFull2Q[K]) isGhostHit(e cacheEntryOut2Q[K]) bool {
+	if !c.adaptive {
+		return true
+	}
+	age := c.outSeq - e.seq
+	switch {
+	case age < c.a1OutSize/2:
+		c.ghostHits += 1
+	case age < c.a1OutSize:
+		c.ghostHits += 1
+		c.oldGhostHits += 1
+	case age < 2*c.a1OutSize:
+		c.shadowHits += 1
+		return false
+	default:
+		return false
+	}
+	return true
+}
 
-	if _, ok := c.itemsOut[key]; ok {
-		cacheEntry.isAm = true
+func (c *ntsFull2Q[K]) trimOut() {
+	for uint64(c.a1out.Len()) > c.outLimit {
+		z := c.a1out.Head()
+		c.a1out.Remove(z)
+		delete(c.itemsOut, z.Value().key)
 	}
+}
 
-	if cacheEntry.isAm {
-		c.am.Enqueue(cacheEntry)
-		c.items[cacheEntry.key] = c.am.Tail()
-	} else {
-		c.a1in.Enqueue(cacheEntry)
-		c.items[cacheEntry.key] = c.a1in.Tail()
+// request - count request of adaptive mode and tune sizes at the end of the window.
+// Hit counters are halved after every window, so they are a sliding estimate where old windows weigh less.
+func (c *ntsFull2Q[K]) request() {
+	if !c.adaptive {
+		return
 	}
+	c.requests += 1
+	if c.requests < c.window {
+		return
+	}
+	c.tune()
+	c.requests = 0
+	c.amHits, c.ghostHits, c.oldGhostHits, c.shadowHits = c.amHits/2, c.ghostHits/2, c.oldGhostHits/2, c.shadowHits/2
 }
 
-func (c *ntsFull2Q[K, T]) reclaim() {
-	if c.totalSize > uint64(c.am.Len()+c.a1in.Len()) {
-		return //there are free slots
+// tune - marginal gain of A1in is ghost hits per ghost, marginal loss of Am is Am hits per slot,
+// a queue gets space only if it is full now. A1out should be longer when keys behind its end
+// get a quarter of ghost hits, and it is too long when it gets hits, but not in its older half.
+func (c *ntsFull2Q[K]) tune() {
+	step := percentOf(c.capacity, full2QStepPercent)
+	minIn := percentOf(c.capacity, full2QMinInPercent)
+	maxIn := percentOf(c.capacity, full2QMaxInPercent)
+	if maxIn >= c.capacity && c.capacity > 1 {
+		maxIn = c.capacity - 1
 	}
-	if uint64(c.a1in.Len()) > c.a1InSize {
-		y := c.a1in.Dequeue()
-		if nil == y {
-			return
-		}
-		delete(c.items, y.Value().key)
-		c.a1out.Enqueue(y.Value().key)
-		c.itemsOut[y.Value().key] = c.a1out.Tail()
-		if uint64(c.a1out.Len()) > c.a1OutSize {
-			z := c.a1out.Dequeue()
-			if z != nil {
-				delete(c.itemsOut, z.Value())
-			}
+	outLen := c.a1OutSize
+	if outLen < 1 {
+		outLen = 1
+	}
+	amSize := c.amSize
+	if amSize < 1 {
+		amSize = 1
+	}
+	ghostGain := c.ghostHits * amSize
+	amLoss := c.amHits * outLen
+	switch {
+	case ghostGain > amLoss && c.a1InSize < maxIn && uint64(c.a1in.Len()) >= c.a1InSize:
+		c.a1InSize += step
+		if c.a1InSize > maxIn {
+			c.a1InSize = maxIn
 		}
-	} else {
-		y := c.am.Dequeue()
-		if y != nil {
-			delete(c.items, y.Value().key)
+	case ghostGain < amLoss && c.a1InSize > minIn && uint64(c.am.Len()) >= c.amSize:
+		if c.a1InSize < minIn+step {
+			c.a1InSize = minIn
+		} else {
+			c.a1InSize -= step
 		}
 	}
-}
+	c.amSize = c.capacity - c.a1InSize
 
-func (c *ntsFull2Q[K, T]) delete(key K) {
-	if e, ok := c.items[key]; ok {
-		if e.Value().isAm {
-			c.am.Remove(e)
+	minOut := percentOf(c.capacity, full2QMinOutPercent)
+	maxOut := percentOf(c.capacity, full2QMaxOutPercent)
+	switch {
+	case c.shadowHits > 0 && 4*c.shadowHits >= c.ghostHits && c.a1OutSize < maxOut:
+		c.a1OutSize += step
+		if c.a1OutSize > maxOut {
+			c.a1OutSize = maxOut
+		}
+	case c.ghostHits > 0 && 0 == c.oldGhostHits+c.shadowHits && c.a1OutSize > minOut:
+		if c.a1OutSize < minOut+step {
+			c.a1OutSize = minOut
 		} else {
-			c.a1in.Remove(e)
+			c.a1OutSize -= step
 		}
-		delete(c.items, key)
-	}
-	if e, ok := c.itemsOut[key]; ok {
-		c.a1out.Remove(e)
-		delete(c.itemsOut, key)
 	}
 }
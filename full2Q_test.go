@@ -8,7 +8,8 @@ import (
 
 type suiteNtsFull2Q struct {
 	suite.Suite
-	cache *ntsFull2Q[string, int]
+	cache  *ntsStore[string, int]
+	policy *ntsFull2Q[string]
 }
 
 func TestNtsFull2Q(t *testing.T) {
@@ -16,7 +17,8 @@ func TestNtsFull2Q(t *testing.T) {
 }
 
 func (s *suiteNtsFull2Q) SetupTest() {
-	s.cache = newNtsFull2Q[string, int](3, 2, 10)
+	s.policy = newNtsFull2Q[string](3, 2, 10)
+	s.cache = newNtsStore[string, int](s.policy, 5, nil)
 	s.cache.put("1", 1)
 	s.cache.put("2", 2)
 	s.cache.put("3", 3)
@@ -70,3 +72,114 @@ func (s *suiteNtsFull2Q) TestTSVersion() {
 	s.False(ok)
 	s.Equal(0, r)
 }
+
+func (s *suiteNtsFull2Q) TestTune() {
+	c := newAdaptiveNtsFull2Q[string](100)
+	s.Equal(uint64(75), c.amSize)
+	s.Equal(uint64(25), c.a1InSize)
+	s.Equal(uint64(50), c.a1OutSize)
+	s.Equal(uint64(400), c.outLimit)
+
+	//both queues are full
+	for i := 0; i < 100; i++ {
+		if i < 25 {
+			c.a1in.Enqueue(cacheEntry2Q[string]{key: strconv.Itoa(i)})
+		} else {
+			c.am.Enqueue(cacheEntry2Q[string]{key: strconv.Itoa(i), isAm: true})
+		}
+	}
+
+	//ghost hits per ghost are larger than Am hits per slot, keys behind the end of A1out are hit too
+	c.ghostHits, c.oldGhostHits, c.shadowHits, c.amHits = 20, 10, 5, 20
+	c.tune()
+	s.Equal(uint64(70), c.amSize)
+	s.Equal(uint64(30), c.a1InSize)
+	s.Equal(uint64(55), c.a1OutSize)
+
+	//Am is more useful, A1out is too long
+	c.ghostHits, c.oldGhostHits, c.shadowHits, c.amHits = 1, 0, 0, 100
+	c.tune()
+	s.Equal(uint64(75), c.amSize)
+	s.Equal(uint64(25), c.a1InSize)
+	s.Equal(uint64(50), c.a1OutSize)
+
+	//Am can't use more space than it has now
+	for i := 0; i < 10; i++ {
+		c.tune()
+	}
+	s.Equal(uint64(20), c.a1InSize)
+	s.Equal(uint64(80), c.amSize)
+	s.Equal(uint64(10), c.a1OutSize)
+}
+
+func (s *suiteNtsFull2Q) TestShadowHits() {
+	c := newAdaptiveNtsFull2Q[string](10)
+	s.Equal(uint64(5), c.a1OutSize)
+	for i := 0; i < 20; i++ {
+		c.OnInsert(strconv.Itoa(i), 1)
+		if i >= 10 {
+			c.OnRemove(strconv.Itoa(i-10), RemoveEvicted)
+		}
+	}
+	s.Equal(10, c.a1out.Len())
+	s.Equal(uint64(0), c.requests)
+
+	//"9" is in A1out, "4" is behind its end
+	c.OnInsert("9", 1)
+	s.True(c.items["9"].Value().isAm)
+	c.OnInsert("4", 1)
+	s.False(c.items["4"].Value().isAm)
+	s.Equal(uint64(1), c.ghostHits)
+	s.Equal(uint64(1), c.shadowHits)
+}
+
+func (s *suiteNtsFull2Q) TestSlidingWindow() {
+	c := newAdaptiveNtsFull2Q[string](10)
+	c.amHits, c.ghostHits, c.oldGhostHits, c.shadowHits = 8, 6, 4, 2
+	c.requests = c.window - 1
+	c.request()
+	s.Equal(uint64(0), c.requests)
+	s.Equal(uint64(4), c.amHits)
+	s.Equal(uint64(3), c.ghostHits)
+	s.Equal(uint64(2), c.oldGhostHits)
+	s.Equal(uint64(1), c.shadowHits)
+}
+
+func (s *suiteNtsFull2Q) TestAdaptive() {
+	//hot keys are read many times between scans and don't fit into Am, Am is worth more than A1in
+	c := NewAdaptiveFull2Q[string, int](100)
+	unique := 0
+	for i := 0; i < 20000; i++ {
+		k := strconv.Itoa(i / 2 % 90)
+		if i%2 == 0 {
+			unique += 1
+			k = "u" + strconv.Itoa(unique)
+		}
+		if _, ok := c.Get(k, 0); !ok {
+			c.Put(k, i)
+		}
+	}
+	split := c.Split()
+	s.Less(split.A1InSize, uint64(25))
+	s.Equal(uint64(100), split.AmSize+split.A1InSize)
+
+	//hot keys fit into smaller Am, other keys are read twice with distance longer than A1in and never again,
+	//so A1in is worth more than Am
+	c = NewAdaptiveFull2Q[string, int](100)
+	for i := 0; i < 30000; i++ {
+		j := i / 3
+		k := "h" + strconv.Itoa(j%40)
+		switch i % 3 {
+		case 1:
+			k = strconv.Itoa(j)
+		case 2:
+			k = strconv.Itoa(j - 35)
+		}
+		if _, ok := c.Get(k, 0); !ok {
+			c.Put(k, i)
+		}
+	}
+	split = c.Split()
+	s.Greater(split.A1InSize, uint64(25))
+	s.Equal(uint64(100), split.AmSize+split.A1InSize)
+}
@@ -0,0 +1,154 @@
+package allcache
+
+import "sync"
+
+// GDSF - Greedy-Dual-Size-Frequency eviction policy for objects of different sizes and costs
+// @see https://www.hpl.hp.com/techreports/98/HPL-98-69R1.pdf
+// Priority of an entry is L + frequency * cost / size, the entry with the lowest priority is evicted
+// and its priority becomes the inflation value L, so entries which were not used for a long time age out.
+type GDSF[K comparable, T any] struct {
+	cache *ntsGDSF[K, T]
+	lock  sync.Mutex
+}
+
+// NewGDSF - maxSize is total size of items, calcCost is cost of item, 1 if nil.
+// Items larger than maxSize are not cached.
+func NewGDSF[K comparable, T any](maxSize uint64, calcSize SizeCalculator[T], calcCost CostCalculator[T]) Cache[K, T] {
+	cache := new(GDSF[K, T])
+	cache.cache = newNtsGDSF[K, T](maxSize, calcSize, calcCost)
+	return cache
+}
+
+func (c *GDSF[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *GDSF[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.get(key, def)
+}
+
+func (c *GDSF[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *GDSF[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe GDSF
+type ntsGDSF[K comparable, T any] struct {
+	items      map[K]*heapItem[float64, *cacheEntryGDSF[K, T]]
+	evictQueue *minHeap[float64, *cacheEntryGDSF[K, T]]
+	// inflation - L, priority of the last evicted entry
+	inflation float64
+
+	length   uint64
+	maxSize  uint64
+	sizeCalc SizeCalculator[T]
+	costCalc CostCalculator[T]
+}
+
+func newNtsGDSF[K comparable, T any](
+	maxSize uint64,
+	sizeCalc SizeCalculator[T],
+	costCalc CostCalculator[T],
+) *ntsGDSF[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	if nil == costCalc {
+		costCalc = func(T) uint64 { return 1 }
+	}
+	return &ntsGDSF[K, T]{
+		items:      make(map[K]*heapItem[float64, *cacheEntryGDSF[K, T]]),
+		evictQueue: newMinHeap[float64, *cacheEntryGDSF[K, T]](0),
+		maxSize:    maxSize,
+		sizeCalc:   sizeCalc,
+		costCalc:   costCalc,
+	}
+}
+
+func (c *ntsGDSF[K, T]) priority(entry *cacheEntryGDSF[K, T]) float64 {
+	size := entry.size
+	if 0 == size {
+		size = 1
+	}
+	return c.inflation + float64(entry.freq)*float64(entry.cost)/float64(size)
+}
+
+func (c *ntsGDSF[K, T]) get(key K, def T) (T, bool) {
+	if e, ok := c.items[key]; ok {
+		entry := e.value
+		entry.freq += 1
+		c.evictQueue.update(e, c.priority(entry))
+		return entry.value, true
+	}
+	return def, false
+}
+
+func (c *ntsGDSF[K, T]) put(key K, value T) {
+	size := c.sizeCalc(value)
+	if size > c.maxSize {
+		c.delete(key)
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		entry := e.value
+		c.length += size - entry.size
+		entry.value = value
+		entry.size = size
+		entry.cost = c.costCalc(value)
+		entry.freq += 1
+		c.evictQueue.update(e, c.priority(entry))
+		c.reclaim(0)
+		return
+	}
+	c.reclaim(size)
+	entry := &cacheEntryGDSF[K, T]{size: size, cost: c.costCalc(value), freq: 1}
+	entry.key = key
+	entry.value = value
+	c.items[key] = c.evictQueue.push(c.priority(entry), entry)
+	c.length += size
+}
+
+func (c *ntsGDSF[K, T]) delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.evictQueue.remove(e)
+		delete(c.items, key)
+		c.length -= e.value.size
+	}
+}
+
+func (c *ntsGDSF[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.value.value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+// reclaim - evict entries with the lowest priority and inflate L up to it
+func (c *ntsGDSF[K, T]) reclaim(size uint64) {
+	for c.length+size > c.maxSize {
+		e := c.evictQueue.pop()
+		if nil == e {
+			return
+		}
+		c.inflation = e.priority
+		delete(c.items, e.value.key)
+		c.length -= e.value.size
+	}
+}
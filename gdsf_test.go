@@ -0,0 +1,115 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsGDSF struct {
+	suite.Suite
+	cache *ntsGDSF[string, int]
+}
+
+func TestNtsGDSF(t *testing.T) {
+	suite.Run(t, new(suiteNtsGDSF))
+}
+
+func (s *suiteNtsGDSF) SetupTest() {
+	s.cache = newNtsGDSF[string, int](10, func(v int) uint64 { return uint64(v) }, nil)
+}
+
+func (s *suiteNtsGDSF) TestSizeAware() {
+	s.cache.put("small", 1)
+	s.cache.put("big", 8)
+	s.cache.put("medium", 2)
+
+	//big item has the lowest priority 1/8 and it becomes L
+	_, ok := s.cache.get("big", 0)
+	s.False(ok)
+	s.Equal(0.125, s.cache.inflation)
+	s.Equal(float64(1), s.cache.items["small"].priority)
+	s.Equal(0.625, s.cache.items["medium"].priority)
+	s.Equal(uint64(3), s.cache.length)
+
+	s.cache.put("big", 11)
+	_, ok = s.cache.get("big", 0)
+	s.False(ok)
+}
+
+func (s *suiteNtsGDSF) TestFrequency() {
+	c := newNtsGDSF[string, int](3, nil, nil)
+	for i := 1; i <= 3; i++ {
+		c.put(strconv.Itoa(i), i)
+	}
+	c.get("1", 0)
+	c.get("1", 0)
+	c.get("3", 0)
+	c.put("4", 4)
+	_, ok := c.get("2", 0)
+	s.False(ok)
+	s.Equal(float64(1), c.inflation)
+	s.Equal(float64(3), c.items["1"].priority)
+	s.Equal(float64(2), c.items["3"].priority)
+	s.Equal(float64(2), c.items["4"].priority)
+}
+
+func (s *suiteNtsGDSF) TestCost() {
+	c := newNtsGDSF[string, int](2, nil, func(v int) uint64 { return uint64(v) })
+	c.put("expensive", 10)
+	c.put("cheap", 1)
+	c.get("cheap", 0)
+	c.put("new", 1)
+	_, ok := c.get("cheap", 0)
+	s.False(ok)
+	_, ok = c.get("expensive", 0)
+	s.True(ok)
+}
+
+// TestAging - frequently used entry leaves the cache after L has grown above its priority
+func (s *suiteNtsGDSF) TestAging() {
+	c := newNtsGDSF[string, int](2, nil, nil)
+	c.put("old", 0)
+	for i := 0; i < 5; i++ {
+		c.get("old", 0)
+	}
+	for i := 0; i < 20; i++ {
+		k := strconv.Itoa(i)
+		c.put(k, i)
+		c.get(k, 0)
+	}
+	_, ok := c.get("old", 0)
+	s.False(ok)
+	s.Equal(2, c.evictQueue.len())
+}
+
+func (s *suiteNtsGDSF) TestPutAndDelete() {
+	s.cache.put("a", 4)
+	s.cache.put("b", 4)
+	s.cache.put("a", 6)
+	s.Equal(uint64(10), s.cache.length)
+	r, ok := s.cache.get("a", 0)
+	s.True(ok)
+	s.Equal(6, r)
+
+	s.cache.delete("a")
+	_, ok = s.cache.get("a", 0)
+	s.False(ok)
+	s.Equal(uint64(4), s.cache.length)
+	s.Equal(1, s.cache.evictQueue.len())
+}
+
+func (s *suiteNtsGDSF) TestTSVersion() {
+	c := NewGDSF[int, int](3, nil, nil)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
@@ -2,12 +2,15 @@ module github.com/satmaelstorm/allcache
 
 go 1.18
 
+require (
+	github.com/satmaelstorm/list v1.2.0
+	github.com/stretchr/testify v1.7.1
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/satmaelstorm/list v1.2.0 // indirect
 	github.com/stretchr/objx v0.3.0 // indirect
-	github.com/stretchr/testify v1.7.1 // indirect
 	golang.org/x/exp v0.0.0-20220428152302-39d4317da171 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
@@ -0,0 +1,65 @@
+package allcache
+
+import "sync/atomic"
+
+// Handle - acquired item of Store, it is not evicted until the handle is released
+type Handle[T any] interface {
+	// Value - value of the item at the moment it was acquired
+	Value() T
+	// Release - the item can be evicted again, eviction callback of removed item is called
+	// after its last handle is released. Release can be called more than once.
+	Release()
+}
+
+type storeHandle[K comparable, T any] struct {
+	store    *Store[K, T]
+	key      K
+	entry    *storeEntry[T]
+	value    T
+	released uint32
+}
+
+func (h *storeHandle[K, T]) Value() T {
+	return h.value
+}
+
+func (h *storeHandle[K, T]) Release() {
+	if !atomic.CompareAndSwapUint32(&h.released, 0, 1) {
+		return
+	}
+	h.store.lock.Lock()
+	defer h.store.unlock()
+	h.store.cache.release(h.key, h.entry)
+}
+
+// Acquire - Get, which holds the item in the store until the handle is released.
+// Acquired item is still deleted, expired or replaced, the handle keeps its value, and eviction callback
+// gets the value after its last handle is released, with RemoveReplaced if it was replaced.
+func (s *Store[K, T]) Acquire(key K) (Handle[T], bool) {
+	s.lock.Lock()
+	defer s.unlock()
+	var zero T
+	if _, ok := s.cache.get(key, zero); !ok {
+		return nil, false
+	}
+	e := s.cache.items[key]
+	if !e.held() {
+		s.cache.hold(key, e)
+	}
+	e.refs += 1
+	return &storeHandle[K, T]{store: s, key: key, entry: e, value: e.value}, true
+}
+
+func (c *ntsStore[K, T]) release(key K, e *storeEntry[T]) {
+	e.refs -= 1
+	if e.refs > 0 {
+		return
+	}
+	if e.detached {
+		c.removeCallback(key, e.value, e.reason)
+		return
+	}
+	if !e.held() {
+		c.unhold(key, e)
+	}
+}
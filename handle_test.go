@@ -0,0 +1,181 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type suiteHandle struct {
+	suite.Suite
+	cache   *Store[string, int]
+	removed []string
+	lock    sync.Mutex
+}
+
+func TestHandle(t *testing.T) {
+	suite.Run(t, new(suiteHandle))
+}
+
+func (s *suiteHandle) SetupTest() {
+	s.removed = nil
+	s.cache = NewStore[string, int](NewLRUPolicy[string](), 3, nil).
+		WithEvictionCallback(func(key string, value int, reason RemoveReason) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			s.removed = append(s.removed, key+"="+strconv.Itoa(value)+":"+reason.String())
+		})
+	for i := 1; i <= 3; i++ {
+		s.cache.Put(strconv.Itoa(i), i)
+	}
+}
+
+func (s *suiteHandle) TestNotEvicted() {
+	h, ok := s.cache.Acquire("1")
+	s.True(ok)
+	s.Equal(1, h.Value())
+	h2, _ := s.cache.Acquire("1")
+
+	s.cache.Put("4", 4)
+	s.cache.Put("5", 5)
+	_, ok = s.cache.Get("1", 0)
+	s.True(ok)
+	s.Equal([]string{"2=2:evicted", "3=3:evicted"}, s.removed)
+
+	//"1" can be evicted again after the last release, it keeps its recency of the last Get
+	h.Release()
+	h.Release()
+	s.Equal(uint64(1), s.cache.cache.heldWeight)
+	h2.Release()
+	s.Equal(uint64(0), s.cache.cache.heldWeight)
+	s.cache.Put("6", 6)
+	s.cache.Put("7", 7)
+	_, ok = s.cache.Get("1", 0)
+	s.True(ok)
+	s.cache.Put("8", 8)
+	s.cache.Put("9", 9)
+	s.cache.Put("10", 10)
+	_, ok = s.cache.Get("1", 0)
+	s.False(ok)
+
+	_, ok = s.cache.Acquire("absent")
+	s.False(ok)
+}
+
+func (s *suiteHandle) TestCallbackAfterRelease() {
+	h, _ := s.cache.Acquire("1")
+	h2, _ := s.cache.Acquire("1")
+	s.cache.Delete("1")
+	_, ok := s.cache.Get("1", 0)
+	s.False(ok)
+	s.Empty(s.removed)
+	s.Equal(uint64(2), s.cache.Weight())
+
+	h.Release()
+	s.Empty(s.removed)
+	s.Equal(1, h2.Value())
+	h2.Release()
+	s.Equal([]string{"1=1:deleted"}, s.removed)
+
+	//new item with the same key is independent
+	s.cache.Put("1", 11)
+	h, _ = s.cache.Acquire("1")
+	s.cache.Put("4", 4)
+	s.cache.Put("5", 5)
+	h.Release()
+	s.Equal([]string{"1=1:deleted", "2=2:evicted", "3=3:evicted"}, s.removed)
+}
+
+func (s *suiteHandle) TestReplaceAcquired() {
+	h, _ := s.cache.Acquire("1")
+	s.cache.Put("1", 10)
+	r, _ := s.cache.Get("1", 0)
+	s.Equal(10, r)
+	s.Equal(1, h.Value())
+	s.Equal(uint64(0), s.cache.cache.heldWeight)
+	s.Empty(s.removed)
+
+	//new value is not acquired, it is evicted and deleted as usual
+	s.cache.Put("4", 4)
+	s.cache.Put("5", 5)
+	s.cache.Put("6", 6)
+	s.cache.Put("7", 7)
+	s.Equal([]string{"2=2:evicted", "3=3:evicted", "1=10:evicted", "4=4:evicted"}, s.removed)
+
+	//old value is reported after the last release
+	h.Release()
+	s.Equal("1=1:replaced", s.removed[4])
+	s.Equal(uint64(3), s.cache.Weight())
+
+	//pinned key stays pinned after replace
+	s.cache.Pin("5")
+	h, _ = s.cache.Acquire("5")
+	s.cache.Put("5", 50)
+	s.Equal(uint64(1), s.cache.cache.heldWeight)
+	h.Release()
+	s.Equal("5=5:replaced", s.removed[5])
+	s.Equal(uint64(1), s.cache.cache.heldWeight)
+}
+
+func (s *suiteHandle) TestKeepsHistory() {
+	c := NewStore[string, int](NewLFUPolicy[string](LFUNoAging, 0), 3, nil)
+	c.Put("hot", 1)
+	for i := 0; i < 5; i++ {
+		c.Get("hot", 0)
+	}
+	h, _ := c.Acquire("hot")
+	c.Get("hot", 0)
+	h.Release()
+	s.Equal(uint64(8), c.cache.policy.(*ntsLFU[string]).items["hot"].value.freq)
+
+	//frequent key is not the next victim after release
+	for i := 0; i < 10; i++ {
+		c.Put(strconv.Itoa(i), i)
+	}
+	_, ok := c.Get("hot", 0)
+	s.True(ok)
+}
+
+func (s *suiteHandle) TestPinnedAndAcquired() {
+	s.True(s.cache.Pin("1"))
+	h, _ := s.cache.Acquire("1")
+	s.True(s.cache.Unpin("1"))
+	s.cache.Put("4", 4)
+	s.cache.Put("5", 5)
+	_, ok := s.cache.Get("1", 0)
+	s.True(ok)
+
+	h2, _ := s.cache.Acquire("4")
+	h3, _ := s.cache.Acquire("5")
+	s.ErrorIs(s.cache.TryPut("6", 6), ErrPinnedOverCapacity)
+	h.Release()
+	s.NoError(s.cache.TryPut("6", 6))
+	_, ok = s.cache.Get("1", 0)
+	s.False(ok)
+	h2.Release()
+	h3.Release()
+}
+
+func (s *suiteHandle) TestConcurrent() {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				k := strconv.Itoa((g + i) % 6)
+				if h, ok := s.cache.Acquire(k); ok {
+					s.cache.Put(strconv.Itoa(i%7), i)
+					h.Release()
+				} else {
+					s.cache.Put(k, i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	s.Equal(uint64(0), s.cache.cache.heldWeight)
+	s.LessOrEqual(s.cache.Weight(), uint64(3))
+	s.Equal(s.cache.Len(), len(s.cache.cache.policy.(*ntsLRU[string]).items))
+}
@@ -0,0 +1,47 @@
+package allcache
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// HashKey - default KeyHasher: FNV-1a of strings, numbers as is, FNV-1a of fmt.Sprint for other keys,
+// then all of them are mixed by murmur3 finalizer. It is used by SHARDS sampler of mrc package too.
+func HashKey[K comparable](key K) uint64 {
+	var h uint64
+	switch k := any(key).(type) {
+	case string:
+		h = hashString(k)
+	case int:
+		h = uint64(k)
+	case int32:
+		h = uint64(k)
+	case int64:
+		h = uint64(k)
+	case uint:
+		h = uint64(k)
+	case uint32:
+		h = uint64(k)
+	case uint64:
+		h = k
+	default:
+		h = hashString(fmt.Sprint(key))
+	}
+	return mixHash(h)
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mixHash - murmur3 finalizer, low bits of FNV of short keys and of sequential numbers are poorly distributed
+func mixHash(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,99 @@
+package allcache
+
+type heapPriority interface {
+	~int64 | ~uint64 | ~float64
+}
+
+type heapItem[P heapPriority, T any] struct {
+	priority P
+	value    T
+	pos      int
+}
+
+// minHeap - binary heap like list.PQ, but with the lowest priority on top and arbitrary priority updates.
+// Items are stored from index 1.
+type minHeap[P heapPriority, T any] struct {
+	items []*heapItem[P, T]
+}
+
+func newMinHeap[P heapPriority, T any](capacity uint64) *minHeap[P, T] {
+	items := make([]*heapItem[P, T], 1, capacity+1)
+	return &minHeap[P, T]{items: items}
+}
+
+func (h *minHeap[P, T]) len() int {
+	return len(h.items) - 1
+}
+
+func (h *minHeap[P, T]) less(i, j int) bool {
+	return h.items[i].priority < h.items[j].priority
+}
+
+func (h *minHeap[P, T]) exch(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].pos = i
+	h.items[j].pos = j
+}
+
+func (h *minHeap[P, T]) swim(k int) {
+	for k > 1 && h.less(k, k/2) {
+		h.exch(k/2, k)
+		k = k / 2
+	}
+}
+
+func (h *minHeap[P, T]) sink(k int) {
+	n := h.len()
+	for 2*k <= n {
+		j := 2 * k
+		if j < n && h.less(j+1, j) {
+			j++
+		}
+		if !h.less(j, k) {
+			break
+		}
+		h.exch(k, j)
+		k = j
+	}
+}
+
+func (h *minHeap[P, T]) push(priority P, value T) *heapItem[P, T] {
+	item := &heapItem[P, T]{priority: priority, value: value, pos: len(h.items)}
+	h.items = append(h.items, item)
+	h.swim(item.pos)
+	return item
+}
+
+// peek - item with the lowest priority or nil
+func (h *minHeap[P, T]) peek() *heapItem[P, T] {
+	if h.len() < 1 {
+		return nil
+	}
+	return h.items[1]
+}
+
+func (h *minHeap[P, T]) pop() *heapItem[P, T] {
+	item := h.peek()
+	if item != nil {
+		h.remove(item)
+	}
+	return item
+}
+
+func (h *minHeap[P, T]) remove(item *heapItem[P, T]) {
+	pos, last := item.pos, h.len()
+	h.exch(pos, last)
+	h.items[last] = nil
+	h.items = h.items[:last]
+	item.pos = 0
+	if pos < last {
+		h.sink(pos)
+		h.swim(pos)
+	}
+}
+
+func (h *minHeap[P, T]) update(item *heapItem[P, T], priority P) {
+	item.priority = priority
+	h.sink(item.pos)
+	h.swim(item.pos)
+}
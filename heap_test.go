@@ -0,0 +1,46 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+type suiteMinHeap struct {
+	suite.Suite
+}
+
+func TestMinHeap(t *testing.T) {
+	suite.Run(t, new(suiteMinHeap))
+}
+
+func (s *suiteMinHeap) TestOrder() {
+	rnd := rand.New(rand.NewSource(1))
+	h := newMinHeap[float64, int](0)
+	items := make([]*heapItem[float64, int], 0, 100)
+	for i := 0; i < 100; i++ {
+		items = append(items, h.push(rnd.Float64(), i))
+	}
+	//remove every third item and change priority of every fifth
+	for i, item := range items {
+		if i%3 == 0 {
+			h.remove(item)
+		} else if i%5 == 0 {
+			h.update(item, rnd.Float64())
+		}
+	}
+	var expected []float64
+	for i, item := range items {
+		if i%3 != 0 {
+			expected = append(expected, item.priority)
+		}
+	}
+	sort.Float64s(expected)
+	s.Equal(len(expected), h.len())
+	for _, p := range expected {
+		s.Equal(p, h.pop().priority)
+	}
+	s.Nil(h.pop())
+	s.Nil(h.peek())
+}
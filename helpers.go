@@ -1 +1,22 @@
 package allcache
+
+import "math"
+
+// Log2QueuesNum - queue number is log2(hits), default of MQ cache
+func Log2QueuesNum(hits uint64) byte {
+	if hits < 1 {
+		return 0
+	}
+	return byte(math.Log2(float64(hits)))
+}
+
+// LinearQueuesNum - queue number is hits-1, every hit promotes entry to the next queue
+func LinearQueuesNum(hits uint64) byte {
+	if hits < 1 {
+		return 0
+	}
+	if hits > math.MaxUint8 {
+		return math.MaxUint8
+	}
+	return byte(hits - 1)
+}
@@ -0,0 +1,19 @@
+package allcache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueuesNum(t *testing.T) {
+	assert.Equal(t, byte(0), Log2QueuesNum(0))
+	assert.Equal(t, byte(0), Log2QueuesNum(1))
+	assert.Equal(t, byte(3), Log2QueuesNum(15))
+	assert.Equal(t, byte(4), Log2QueuesNum(16))
+
+	assert.Equal(t, byte(0), LinearQueuesNum(0))
+	assert.Equal(t, byte(0), LinearQueuesNum(1))
+	assert.Equal(t, byte(9), LinearQueuesNum(10))
+	assert.Equal(t, byte(255), LinearQueuesNum(1000))
+}
@@ -1,78 +1,165 @@
 package allcache
 
-import (
-	"github.com/satmaelstorm/list"
-	"sync"
+// LFUAging - how LFU forgets frequencies of entries which were popular long ago
+type LFUAging byte
+
+const (
+	// LFUNoAging - classic LFU, frequencies are never decreased
+	LFUNoAging LFUAging = iota
+	// LFUDynamicAging - LFU-DA, priority of entry is frequency + L, where L is priority of the last evicted entry
+	// @see https://www.hpl.hp.com/techreports/98/HPL-98-173.pdf
+	LFUDynamicAging
+	// LFUHalving - frequencies of all entries are halved every halvingPeriod of puts and gets
+	LFUHalving
 )
 
+// lfuDefaultHalvingPeriod - halving period of LFU policy without known capacity
+const lfuDefaultHalvingPeriod = 10000
+
 type LFU[K comparable, T any] struct {
-	cache *ntsLFU[K, T]
-	lock  sync.Mutex
+	*Store[K, T]
+	policy *ntsLFU[K]
 }
 
 func NewLFU[K comparable, T any](maxSize int) Cache[K, T] {
-	cache := new(LFU[K, T])
-	cache.cache = newNtsLFU[K, T](maxSize)
-	return cache
+	return NewLFUWithAging[K, T](maxSize, LFUNoAging, 0)
 }
 
-func (c *LFU[K, T]) Put(key K, item T) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.cache.put(key, item)
+// NewLFUWithAging - LFU with aging, halvingPeriod is used by LFUHalving only
+func NewLFUWithAging[K comparable, T any](maxSize int, aging LFUAging, halvingPeriod uint64) *LFU[K, T] {
+	if maxSize < 0 {
+		maxSize = 0
+	}
+	policy := newNtsLFU[K](maxSize, aging, halvingPeriod)
+	return &LFU[K, T]{Store: NewStore[K, T](policy, uint64(maxSize), nil), policy: policy}
 }
 
-func (c *LFU[K, T]) Get(key K, def T) (T, bool) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.cache.get(key, def)
+// NewLFUPolicy - LFU policy for Store, halvingPeriod is used by LFUHalving only, weights are ignored
+func NewLFUPolicy[K comparable](aging LFUAging, halvingPeriod uint64) Policy[K] {
+	return newNtsLFU[K](0, aging, halvingPeriod)
 }
 
-func (c *LFU[K, T]) Delete(key K) {
+// AgeFactor - for LFUDynamicAging it is L, priority of the last evicted entry,
+// for LFUHalving it is number of times frequencies were halved, 0 without aging
+func (c *LFU[K, T]) AgeFactor() uint64 {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	c.cache.delete(key)
+	return c.policy.ageFactor
 }
 
-type ntsLFU[K comparable, T any] struct {
-	items      map[K]*list.PqItem[int64, cacheEntry[K, T]]
-	evictQueue *list.PQ[int64, cacheEntry[K, T]]
+type ntsLFU[K comparable] struct {
+	items      map[K]*heapItem[uint64, *cacheEntryLFU[K]]
+	evictQueue *minHeap[uint64, *cacheEntryLFU[K]]
+	// held - pinned and acquired entries, they are out of the heap, but keep their frequencies
+	held map[K]*heapItem[uint64, *cacheEntryLFU[K]]
 
-	maxSize int
+	aging         LFUAging
+	ageFactor     uint64
+	halvingPeriod uint64
+	accesses      uint64
 }
 
-func newNtsLFU[K comparable, T any](maxSize int) *ntsLFU[K, T] {
-	return &ntsLFU[K, T]{
-		items:      make(map[K]*list.PqItem[int64, cacheEntry[K, T]], maxSize),
-		evictQueue: list.NewPQ[int64, cacheEntry[K, T]](maxSize),
+// newNtsLFU - maxSize is used as default halvingPeriod only
+func newNtsLFU[K comparable](maxSize int, aging LFUAging, halvingPeriod uint64) *ntsLFU[K] {
+	if 0 == halvingPeriod {
+		halvingPeriod = 10 * uint64(maxSize)
+	}
+	if 0 == halvingPeriod {
+		halvingPeriod = lfuDefaultHalvingPeriod
+	}
+	return &ntsLFU[K]{
+		items:         make(map[K]*heapItem[uint64, *cacheEntryLFU[K]], maxSize),
+		evictQueue:    newMinHeap[uint64, *cacheEntryLFU[K]](uint64(maxSize)),
+		held:          make(map[K]*heapItem[uint64, *cacheEntryLFU[K]]),
+		aging:         aging,
+		halvingPeriod: halvingPeriod,
 	}
 }
 
-func (c *ntsLFU[K, T]) put(key K, value T) {
-	if e, ok := c.items[key]; ok {
-		entry := e.GetValue()
-		entry.value = value
-		e.SetValue(entry)
+// OnInsert - replaced value doesn't change frequency
+func (c *ntsLFU[K]) OnInsert(key K, _ uint64) {
+	c.access()
+	if _, ok := c.items[key]; ok {
+		return
+	}
+	if _, ok := c.held[key]; ok {
 		return
 	}
-	added, oust := c.evictQueue.EnqueueWithOust(-1, cacheEntry[K, T]{key: key, value: value})
-	if oust != nil {
-		delete(c.items, oust.GetValue().key)
+	entry := &cacheEntryLFU[K]{key: key, freq: 1}
+	c.items[key] = c.evictQueue.push(c.priority(entry), entry)
+}
+
+func (c *ntsLFU[K]) OnAccess(key K) {
+	c.access()
+	if e, ok := c.items[key]; ok {
+		e.value.freq += 1
+		c.evictQueue.update(e, c.priority(e.value))
+	} else if e, ok := c.held[key]; ok {
+		e.value.freq += 1
 	}
-	c.items[key] = added
 }
 
-func (c *ntsLFU[K, T]) get(key K, def T) (T, bool) {
+// OnRemove - evicted entry raises L of LFU-DA
+func (c *ntsLFU[K]) OnRemove(key K, reason RemoveReason) {
 	if e, ok := c.items[key]; ok {
-		c.evictQueue.DecInPosition(e.GetIndex())
-		return e.GetValue().value, true
+		if LFUDynamicAging == c.aging && RemoveEvicted == reason {
+			c.ageFactor = e.priority
+		}
+		delete(c.items, key)
+		c.evictQueue.remove(e)
 	}
-	return def, false
+	delete(c.held, key)
 }
 
-func (c *ntsLFU[K, T]) delete(key K) {
+// OnHold - held entry leaves the heap, so it is never the victim
+func (c *ntsLFU[K]) OnHold(key K) {
 	if e, ok := c.items[key]; ok {
 		delete(c.items, key)
-		c.evictQueue.Delete(e)
+		c.evictQueue.remove(e)
+		c.held[key] = e
+	}
+}
+
+// OnUnhold - entry comes back to the heap with its frequency
+func (c *ntsLFU[K]) OnUnhold(key K) {
+	if e, ok := c.held[key]; ok {
+		delete(c.held, key)
+		c.items[key] = c.evictQueue.push(c.priority(e.value), e.value)
+	}
+}
+
+func (c *ntsLFU[K]) Victim() (K, bool) {
+	if e := c.evictQueue.peek(); e != nil {
+		return e.value.key, true
+	}
+	var zero K
+	return zero, false
+}
+
+func (c *ntsLFU[K]) priority(entry *cacheEntryLFU[K]) uint64 {
+	if LFUDynamicAging == c.aging {
+		return c.ageFactor + entry.freq
+	}
+	return entry.freq
+}
+
+// access - count puts and gets for LFUHalving
+func (c *ntsLFU[K]) access() {
+	if c.aging != LFUHalving {
+		return
+	}
+	c.accesses += 1
+	if c.accesses < c.halvingPeriod {
+		return
+	}
+	c.accesses = 0
+	c.ageFactor += 1
+	//halving keeps order of priorities, so the heap stays valid
+	for _, e := range c.evictQueue.items[1:] {
+		e.value.freq /= 2
+		e.priority = e.value.freq
+	}
+	for _, e := range c.held {
+		e.value.freq /= 2
 	}
 }
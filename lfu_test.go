@@ -2,12 +2,19 @@ package allcache
 
 import (
 	"github.com/stretchr/testify/suite"
+	"strconv"
 	"testing"
 )
 
 type suiteNtsLFU struct {
 	suite.Suite
-	cache *ntsLFU[string, int]
+	cache  *ntsStore[string, int]
+	policy *ntsLFU[string]
+}
+
+func newTestLFU(maxSize int, aging LFUAging, halvingPeriod uint64) (*ntsStore[string, int], *ntsLFU[string]) {
+	policy := newNtsLFU[string](maxSize, aging, halvingPeriod)
+	return newNtsStore[string, int](policy, uint64(maxSize), nil), policy
 }
 
 func TestNtsLfu(t *testing.T) {
@@ -17,7 +24,7 @@ func TestNtsLfu(t *testing.T) {
 func (s *suiteNtsLFU) SetupTest() {
 	keysStream := []string{"1", "2", "3", "4", "5", "6", "7"}
 	valuesStream := []int{1, 2, 3, 4, 5, 6, 7}
-	s.cache = newNtsLFU[string, int](5)
+	s.cache, s.policy = newTestLFU(5, LFUNoAging, 0)
 	for i := 0; i < len(valuesStream); i++ {
 		k := keysStream[i]
 		v := valuesStream[i]
@@ -85,6 +92,53 @@ func (s *suiteNtsLFU) TestPutCache() {
 	s.Equal(10, r)
 }
 
+func (s *suiteNtsLFU) TestDynamicAging() {
+	c, p := newTestLFU(2, LFUDynamicAging, 0)
+	c.put("old", 1)
+	for i := 0; i < 6; i++ {
+		c.get("old", 0)
+	}
+	//every new entry is used twice, evicted entry raises L, so old entry finally leaves the cache
+	for i := 0; i < 5; i++ {
+		k := strconv.Itoa(i)
+		c.put(k, i)
+		c.get(k, 0)
+		s.Equal(i < 4, p.items["old"] != nil, i)
+	}
+	s.Equal(uint64(7), p.ageFactor)
+	s.Equal(uint64(9), p.items["4"].priority)
+}
+
+func (s *suiteNtsLFU) TestHalving() {
+	c, p := newTestLFU(2, LFUHalving, 4)
+	c.put("old", 1)
+	for i := 0; i < 7; i++ {
+		c.get("old", 0)
+	}
+	//frequency was halved before the 4th and the 8th access
+	s.Equal(uint64(3), p.items["old"].value.freq)
+	s.Equal(uint64(2), p.ageFactor)
+
+	c.put("a", 1)
+	c.get("a", 0)
+	c.get("a", 0)
+	c.get("a", 0)
+	c.put("b", 2)
+	_, ok := c.get("old", 0)
+	s.False(ok)
+	r, ok := c.get("a", 0)
+	s.True(ok)
+	s.Equal(1, r)
+}
+
+func (s *suiteNtsLFU) TestAgeFactor() {
+	c := NewLFUWithAging[int, int](1, LFUDynamicAging, 0)
+	c.Put(1, 1)
+	c.Get(1, 0)
+	c.Put(2, 2)
+	s.Equal(uint64(2), c.AgeFactor())
+}
+
 func (s *suiteNtsLFU) TestTSVersion() {
 	c := NewLFU[int, int](3)
 	c.Put(1, 1)
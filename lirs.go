@@ -0,0 +1,300 @@
+package allcache
+
+import (
+	"github.com/satmaelstorm/list"
+	"sync"
+)
+
+// LIRS - Low Inter-reference Recency Set @see https://dl.acm.org/doi/10.1145/511334.511340
+type LIRS[K comparable, T any] struct {
+	cache *ntsLIRS[K, T]
+	lock  sync.Mutex
+}
+
+// NewLIRS - maxSize is total capacity, hirSize is capacity of resident HIR entries (about 1% of maxSize),
+// maxGhosts limits number of non-resident HIR entries kept in stack S.
+// Items larger than maxSize are not cached.
+func NewLIRS[K comparable, T any](
+	maxSize, hirSize, maxGhosts uint64,
+	calcSize SizeCalculator[T],
+) Cache[K, T] {
+	cache := new(LIRS[K, T])
+	cache.cache = newNtsLIRS[K, T](maxSize, hirSize, maxGhosts, calcSize)
+	return cache
+}
+
+func (c *LIRS[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *LIRS[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.get(key, def)
+}
+
+func (c *LIRS[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *LIRS[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe LIRS
+// stack S and queue Q are queues with top at the tail, bottom of S and front of Q at the head
+type ntsLIRS[K comparable, T any] struct {
+	items  map[K]*cacheEntryLIRS[K, T]
+	s      *list.Queue[K]
+	q      *list.Queue[K]
+	ghosts *list.Queue[K]
+
+	maxSize   uint64
+	lirSize   uint64
+	maxGhosts uint64
+
+	lirWeight uint64
+	hirWeight uint64
+
+	sizeCalc SizeCalculator[T]
+}
+
+func newNtsLIRS[K comparable, T any](
+	maxSize, hirSize, maxGhosts uint64,
+	sizeCalc SizeCalculator[T],
+) *ntsLIRS[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	if hirSize > maxSize {
+		hirSize = maxSize
+	}
+	return &ntsLIRS[K, T]{
+		items:     make(map[K]*cacheEntryLIRS[K, T], maxSize),
+		s:         list.NewQueue[K](),
+		q:         list.NewQueue[K](),
+		ghosts:    list.NewQueue[K](),
+		maxSize:   maxSize,
+		lirSize:   maxSize - hirSize,
+		maxGhosts: maxGhosts,
+		sizeCalc:  sizeCalc,
+	}
+}
+
+func (c *ntsLIRS[K, T]) get(key K, def T) (T, bool) {
+	e, ok := c.items[key]
+	if !ok || lirsGhost == e.state {
+		return def, false
+	}
+	c.access(e)
+	return e.value, true
+}
+
+func (c *ntsLIRS[K, T]) put(key K, value T) {
+	size := c.sizeCalc(value)
+	e, ok := c.items[key]
+	if size > c.maxSize {
+		if ok {
+			c.remove(e)
+		}
+		return
+	}
+
+	if ok && e.state != lirsGhost {
+		if lirsLIR == e.state {
+			c.lirWeight += size - e.size
+		} else {
+			c.hirWeight += size - e.size
+		}
+		e.value = value
+		e.size = size
+		c.access(e)
+		c.reclaim(0)
+		if lirsLIR == e.state {
+			c.shrinkLIR(e)
+		}
+		return
+	}
+
+	//ghost hit is taken out before reclaim, which can prune it from S
+	isGhost := ok
+	if ok {
+		c.remove(e)
+	}
+	c.reclaim(size)
+
+	e = &cacheEntryLIRS[K, T]{size: size}
+	e.key = key
+	e.value = value
+	c.items[key] = e
+	if isGhost || c.lirWeight+size <= c.lirSize {
+		e.state = lirsLIR
+		c.lirWeight += size
+		c.pushS(e)
+		c.shrinkLIR(e)
+		return
+	}
+	e.state = lirsHIR
+	c.hirWeight += size
+	c.pushS(e)
+	c.pushQ(e)
+}
+
+func (c *ntsLIRS[K, T]) delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+		c.prune()
+	}
+}
+
+func (c *ntsLIRS[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if lirsGhost == e.state {
+			continue
+		}
+		if f(k, e.value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+// access - hit of resident entry
+func (c *ntsLIRS[K, T]) access(e *cacheEntryLIRS[K, T]) {
+	if lirsLIR == e.state {
+		wasBottom := c.s.Head() == e.s
+		c.s.MoveToBack(e.s)
+		if wasBottom {
+			c.prune()
+		}
+		return
+	}
+	if e.s != nil {
+		//HIR with small recency becomes LIR
+		c.q.Remove(e.q)
+		e.q = nil
+		c.hirWeight -= e.size
+		e.state = lirsLIR
+		c.lirWeight += e.size
+		c.s.MoveToBack(e.s)
+		c.shrinkLIR(e)
+		return
+	}
+	c.pushS(e)
+	c.q.MoveToBack(e.q)
+}
+
+// remove - remove entry from all structures, S is not pruned
+func (c *ntsLIRS[K, T]) remove(e *cacheEntryLIRS[K, T]) {
+	if e.s != nil {
+		c.s.Remove(e.s)
+		e.s = nil
+	}
+	switch e.state {
+	case lirsLIR:
+		c.lirWeight -= e.size
+	case lirsHIR:
+		c.q.Remove(e.q)
+		c.hirWeight -= e.size
+	case lirsGhost:
+		c.ghosts.Remove(e.q)
+	}
+	e.q = nil
+	delete(c.items, e.key)
+}
+
+// prune - remove HIR entries from the bottom of S until bottom is LIR
+func (c *ntsLIRS[K, T]) prune() {
+	for h := c.s.Head(); h != nil; h = c.s.Head() {
+		e := c.items[h.Value()]
+		if lirsLIR == e.state {
+			return
+		}
+		c.s.Dequeue()
+		e.s = nil
+		if lirsGhost == e.state {
+			c.remove(e)
+		}
+	}
+}
+
+// demote - bottom LIR entry of S becomes resident HIR
+func (c *ntsLIRS[K, T]) demote() bool {
+	c.prune()
+	h := c.s.Dequeue()
+	if nil == h {
+		return false
+	}
+	e := c.items[h.Value()]
+	e.s = nil
+	e.state = lirsHIR
+	c.lirWeight -= e.size
+	c.hirWeight += e.size
+	c.pushQ(e)
+	c.prune()
+	return true
+}
+
+// shrinkLIR - demote bottom LIR entries while LIR set is too large, but keep e
+func (c *ntsLIRS[K, T]) shrinkLIR(e *cacheEntryLIRS[K, T]) {
+	for c.lirWeight > c.lirSize && c.s.Head() != e.s && c.demote() {
+	}
+}
+
+// reclaim - evict resident HIR entries until size fits into cache
+func (c *ntsLIRS[K, T]) reclaim(size uint64) {
+	for c.lirWeight+c.hirWeight+size > c.maxSize {
+		h := c.q.Dequeue()
+		if nil == h {
+			if !c.demote() {
+				return
+			}
+			continue
+		}
+		e := c.items[h.Value()]
+		e.q = nil
+		c.hirWeight -= e.size
+		if nil == e.s {
+			delete(c.items, e.key)
+			continue
+		}
+		var zero T
+		e.value = zero
+		e.state = lirsGhost
+		c.ghosts.Enqueue(e.key)
+		e.q = c.ghosts.Tail()
+		c.trimGhosts()
+	}
+}
+
+func (c *ntsLIRS[K, T]) trimGhosts() {
+	for uint64(c.ghosts.Len()) > c.maxGhosts {
+		g := c.ghosts.Dequeue()
+		e := c.items[g.Value()]
+		e.q = nil
+		c.s.Remove(e.s)
+		e.s = nil
+		delete(c.items, e.key)
+	}
+	c.prune()
+}
+
+func (c *ntsLIRS[K, T]) pushS(e *cacheEntryLIRS[K, T]) {
+	c.s.Enqueue(e.key)
+	e.s = c.s.Tail()
+}
+
+func (c *ntsLIRS[K, T]) pushQ(e *cacheEntryLIRS[K, T]) {
+	c.q.Enqueue(e.key)
+	e.q = c.q.Tail()
+}
@@ -0,0 +1,167 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsLIRS struct {
+	suite.Suite
+	cache *ntsLIRS[string, int]
+}
+
+func TestNtsLIRS(t *testing.T) {
+	suite.Run(t, new(suiteNtsLIRS))
+}
+
+func (s *suiteNtsLIRS) SetupTest() {
+	s.cache = newNtsLIRS[string, int](3, 1, 10, nil)
+	s.cache.put("A", 1)
+	s.cache.put("B", 2)
+	s.cache.put("C", 3)
+	s.cache.put("D", 4)
+}
+
+func (s *suiteNtsLIRS) stack() []string {
+	var result []string
+	for n := s.cache.s.Head(); n != nil; n = n.Next() {
+		result = append(result, n.Value())
+	}
+	return result
+}
+
+func (s *suiteNtsLIRS) TestFill() {
+	s.Equal(lirsLIR, s.cache.items["A"].state)
+	s.Equal(lirsLIR, s.cache.items["B"].state)
+	s.Equal(lirsGhost, s.cache.items["C"].state)
+	s.Equal(lirsHIR, s.cache.items["D"].state)
+	s.Equal([]string{"A", "B", "C", "D"}, s.stack())
+	s.Equal(uint64(2), s.cache.lirWeight)
+	s.Equal(uint64(1), s.cache.hirWeight)
+
+	r, ok := s.cache.get("C", 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
+
+func (s *suiteNtsLIRS) TestGhostHit() {
+	r, ok := s.cache.get("A", 0)
+	s.True(ok)
+	s.Equal(1, r)
+	s.Equal([]string{"B", "C", "D", "A"}, s.stack())
+
+	s.cache.put("C", 30)
+	s.Equal(lirsLIR, s.cache.items["C"].state)
+	s.Equal(lirsHIR, s.cache.items["B"].state)
+	s.Equal([]string{"A", "C"}, s.stack())
+	_, ok = s.cache.items["D"]
+	s.False(ok)
+
+	r, ok = s.cache.get("B", 0)
+	s.True(ok)
+	s.Equal(2, r)
+	s.Equal([]string{"A", "C", "B"}, s.stack())
+
+	r, ok = s.cache.get("C", 0)
+	s.True(ok)
+	s.Equal(30, r)
+
+	//B has smaller recency than bottom LIR A and becomes LIR
+	s.cache.get("B", 0)
+	s.Equal(lirsLIR, s.cache.items["B"].state)
+	s.Equal(lirsHIR, s.cache.items["A"].state)
+	s.Equal([]string{"C", "B"}, s.stack())
+}
+
+func (s *suiteNtsLIRS) TestDelete() {
+	s.cache.delete("A")
+	s.Equal([]string{"B", "C", "D"}, s.stack())
+	s.Equal(uint64(1), s.cache.lirWeight)
+
+	s.cache.delete("B")
+	s.Empty(s.stack())
+	s.Equal(1, s.cache.q.Len())
+	s.Equal(0, s.cache.ghosts.Len())
+
+	r, ok := s.cache.get("D", 0)
+	s.True(ok)
+	s.Equal(4, r)
+	s.cache.delete("D")
+	s.Empty(s.cache.items)
+	s.Equal(uint64(0), s.cache.lirWeight+s.cache.hirWeight)
+}
+
+func (s *suiteNtsLIRS) TestGhostsLimit() {
+	c := newNtsLIRS[int, int](3, 1, 2, nil)
+	for i := 0; i < 20; i++ {
+		c.put(i, i)
+	}
+	s.LessOrEqual(c.ghosts.Len(), 2)
+	s.LessOrEqual(len(c.items), 5)
+	s.Equal(uint64(3), c.lirWeight+c.hirWeight)
+}
+
+func (s *suiteNtsLIRS) TestLoop() {
+	//loop slightly larger than cache: LRU has no hits, LIRS keeps LIR set
+	lirs := newNtsLIRS[int, int](10, 1, 20, nil)
+	lru := newNtsStore[int, int](newNtsLRU[int](), 10, nil)
+	lirsHits, lruHits := 0, 0
+	for i := 0; i < 1000; i++ {
+		k := i % 12
+		if _, ok := lirs.get(k, 0); ok {
+			lirsHits += 1
+		} else {
+			lirs.put(k, k)
+		}
+		if _, ok := lru.get(k, 0); ok {
+			lruHits += 1
+		} else {
+			lru.put(k, k)
+		}
+	}
+	s.Equal(0, lruHits)
+	s.Greater(lirsHits, 700)
+}
+
+func (s *suiteNtsLIRS) TestSizeCalculator() {
+	c := newNtsLIRS[string, int](100, 10, 10, func(v int) uint64 { return uint64(v) })
+	c.put("a", 50)
+	c.put("b", 40)
+	c.put("c", 10)
+	s.Equal(uint64(90), c.lirWeight)
+	s.Equal(uint64(10), c.hirWeight)
+
+	c.put("big", 101)
+	_, ok := c.get("big", 0)
+	s.False(ok)
+
+	c.put("d", 30)
+	s.LessOrEqual(c.lirWeight+c.hirWeight, uint64(100))
+	r, ok := c.get("d", 0)
+	s.True(ok)
+	s.Equal(30, r)
+
+	c.put("d", 5)
+	s.LessOrEqual(c.lirWeight+c.hirWeight, uint64(100))
+	for i := 0; i < 50; i++ {
+		c.put(strconv.Itoa(i), i%20+1)
+		s.LessOrEqual(c.lirWeight+c.hirWeight, uint64(100))
+		s.LessOrEqual(c.lirWeight, uint64(90))
+	}
+}
+
+func (s *suiteNtsLIRS) TestTSVersion() {
+	c := NewLIRS[int, int](3, 1, 3, nil)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
@@ -7,7 +7,8 @@ import (
 
 type suiteNtsLRU struct {
 	suite.Suite
-	cache *ntsLRU[string, int]
+	cache  *ntsStore[string, int]
+	policy *ntsLRU[string]
 }
 
 func TestNtsLru(t *testing.T) {
@@ -17,7 +18,8 @@ func TestNtsLru(t *testing.T) {
 func (s *suiteNtsLRU) SetupTest() {
 	keysStream := []string{"1", "2", "3", "4", "5", "6", "7"}
 	valuesStream := []int{1, 2, 3, 4, 5, 6, 7}
-	s.cache = newNtsLRU[string, int](5, nil)
+	s.policy = newNtsLRU[string]()
+	s.cache = newNtsStore[string, int](s.policy, 5, nil)
 	for i := 0; i < len(valuesStream); i++ {
 		k := keysStream[i]
 		v := valuesStream[i]
@@ -65,6 +67,9 @@ func (s *suiteNtsLRU) TestDeleteCache() {
 	r, ok = s.cache.get("7", 0)
 	s.False(ok)
 	s.Equal(0, r)
+
+	s.Equal(4, s.policy.evictQueue.Len())
+	s.Equal(uint64(4), s.cache.weight)
 }
 
 func (s *suiteNtsLRU) TestGetCache() {
@@ -73,13 +78,13 @@ func (s *suiteNtsLRU) TestGetCache() {
 	s.True(ok)
 	s.Equal(5, r)
 
-	s.Equal(5, s.cache.evictQueue.Tail().Value().value)
+	s.Equal("5", s.policy.evictQueue.Tail().Value())
 
 	r, ok = s.cache.get("4", 0)
 	s.True(ok)
 	s.Equal(4, r)
 
-	s.Equal(4, s.cache.evictQueue.Tail().Value().value)
+	s.Equal("4", s.policy.evictQueue.Tail().Value())
 
 	r, ok = s.cache.get("key", 0)
 	s.False(ok)
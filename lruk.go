@@ -0,0 +1,275 @@
+package allcache
+
+import (
+	"github.com/satmaelstorm/list"
+	"sync"
+)
+
+// LRUK - LRU-K eviction policy, evicts the entry with the oldest K-th most recent reference
+// @see https://dl.acm.org/doi/10.1145/170036.170081
+type LRUK[K comparable, T any] struct {
+	cache *ntsLRUK[K, T]
+	lock  sync.Mutex
+}
+
+// NewLRUK - k is number of tracked references (2 for LRU-2), capacity is total size of items.
+// historySize limits number of evicted keys whose references are retained.
+// References within correlatedPeriod (measured in references to the cache) after the last one are correlated:
+// they are counted as one and the entry can't be evicted during this period while there are other candidates.
+// Items larger than capacity are not cached.
+func NewLRUK[K comparable, T any](
+	k int,
+	capacity, historySize, correlatedPeriod uint64,
+	calcSize SizeCalculator[T],
+) Cache[K, T] {
+	cache := new(LRUK[K, T])
+	cache.cache = newNtsLRUK[K, T](k, capacity, historySize, correlatedPeriod, calcSize)
+	return cache
+}
+
+func (c *LRUK[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *LRUK[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.get(key, def)
+}
+
+func (c *LRUK[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *LRUK[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe LRU-K.
+// Entries with less than K references have infinite backward K-distance,
+// they are evicted first in LRU order of the last reference from young queue.
+// Entries with K references are in heap ordered by time of K-th reference.
+type ntsLRUK[K comparable, T any] struct {
+	items map[K]*cacheEntryLRUK[K, T]
+	young *list.Queue[*cacheEntryLRUK[K, T]]
+	old   *minHeap[uint64, *cacheEntryLRUK[K, T]]
+
+	itemsHistory map[K]*list.Node[cacheEntryHistory[K]]
+	history      *list.Queue[cacheEntryHistory[K]]
+
+	k                int
+	time             uint64
+	length           uint64
+	capacity         uint64
+	historySize      uint64
+	correlatedPeriod uint64
+
+	sizeCalc SizeCalculator[T]
+}
+
+func newNtsLRUK[K comparable, T any](
+	k int,
+	capacity, historySize, correlatedPeriod uint64,
+	sizeCalc SizeCalculator[T],
+) *ntsLRUK[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	if k < 1 {
+		k = 1
+	}
+	return &ntsLRUK[K, T]{
+		items: make(map[K]*cacheEntryLRUK[K, T], capacity),
+		young: list.NewQueue[*cacheEntryLRUK[K, T]](),
+		old:   newMinHeap[uint64, *cacheEntryLRUK[K, T]](0),
+
+		itemsHistory: make(map[K]*list.Node[cacheEntryHistory[K]], historySize),
+		history:      list.NewQueue[cacheEntryHistory[K]](),
+
+		k:                k,
+		capacity:         capacity,
+		historySize:      historySize,
+		correlatedPeriod: correlatedPeriod,
+
+		sizeCalc: sizeCalc,
+	}
+}
+
+func (c *ntsLRUK[K, T]) get(key K, def T) (T, bool) {
+	c.time += 1
+	if entry, ok := c.items[key]; ok {
+		c.reference(entry)
+		return entry.value, true
+	}
+	return def, false
+}
+
+func (c *ntsLRUK[K, T]) put(key K, value T) {
+	c.time += 1
+	size := c.sizeCalc(value)
+	if size > c.capacity {
+		c.delete(key)
+		return
+	}
+	if entry, ok := c.items[key]; ok {
+		c.length += size - entry.size
+		entry.value = value
+		entry.size = size
+		c.reference(entry)
+		c.reclaim(0)
+		return
+	}
+
+	c.reclaim(size)
+
+	entry := &cacheEntryLRUK[K, T]{size: size, hist: make([]uint64, c.k), last: c.time}
+	entry.key = key
+	entry.value = value
+	if h, ok := c.itemsHistory[key]; ok {
+		copy(entry.hist[1:], h.Value().hist)
+		c.removeHistory(h)
+	}
+	entry.hist[0] = c.time
+	c.items[key] = entry
+	c.length += size
+	c.enqueue(entry)
+}
+
+func (c *ntsLRUK[K, T]) delete(key K) {
+	if entry, ok := c.items[key]; ok {
+		c.remove(entry)
+	}
+	if h, ok := c.itemsHistory[key]; ok {
+		c.removeHistory(h)
+	}
+}
+
+func (c *ntsLRUK[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+// reference - uncorrelated reference shifts history and closes correlated period of previous one,
+// which is excluded from interarrival times
+func (c *ntsLRUK[K, T]) reference(entry *cacheEntryLRUK[K, T]) {
+	if c.isCorrelated(entry) {
+		entry.last = c.time
+		if entry.young != nil {
+			c.young.MoveToBack(entry.young)
+		}
+		return
+	}
+	correlated := entry.last - entry.hist[0]
+	for i := len(entry.hist) - 1; i > 0; i-- {
+		if entry.hist[i-1] != 0 {
+			entry.hist[i] = entry.hist[i-1] + correlated
+		}
+	}
+	entry.hist[0] = c.time
+	entry.last = c.time
+	if entry.old != nil {
+		c.old.update(entry.old, entry.hist[c.k-1])
+		return
+	}
+	c.young.Remove(entry.young)
+	entry.young = nil
+	c.enqueue(entry)
+}
+
+// enqueue - entry goes to young queue or heap depending on number of references
+func (c *ntsLRUK[K, T]) enqueue(entry *cacheEntryLRUK[K, T]) {
+	if kth := entry.hist[c.k-1]; kth != 0 {
+		entry.old = c.old.push(kth, entry)
+		return
+	}
+	c.young.Enqueue(entry)
+	entry.young = c.young.Tail()
+}
+
+// reclaim - evict entries out of correlated period with the oldest K-th reference,
+// if all entries are in correlated period, the oldest one is evicted
+func (c *ntsLRUK[K, T]) reclaim(size uint64) {
+	for c.length+size > c.capacity {
+		victim := c.victim()
+		if nil == victim {
+			return
+		}
+		c.remove(victim)
+		c.retain(victim)
+	}
+}
+
+func (c *ntsLRUK[K, T]) victim() *cacheEntryLRUK[K, T] {
+	if h := c.young.Head(); h != nil && !c.isCorrelated(h.Value()) {
+		return h.Value()
+	}
+	var skipped []*heapItem[uint64, *cacheEntryLRUK[K, T]]
+	var victim *cacheEntryLRUK[K, T]
+	for item := c.old.pop(); item != nil; item = c.old.pop() {
+		skipped = append(skipped, item)
+		if !c.isCorrelated(item.value) {
+			victim = item.value
+			break
+		}
+	}
+	for _, item := range skipped {
+		item.value.old = c.old.push(item.priority, item.value)
+	}
+	if victim != nil {
+		return victim
+	}
+	if h := c.young.Head(); h != nil {
+		return h.Value()
+	}
+	if item := c.old.peek(); item != nil {
+		return item.value
+	}
+	return nil
+}
+
+func (c *ntsLRUK[K, T]) isCorrelated(entry *cacheEntryLRUK[K, T]) bool {
+	return c.time-entry.last <= c.correlatedPeriod
+}
+
+func (c *ntsLRUK[K, T]) remove(entry *cacheEntryLRUK[K, T]) {
+	if entry.old != nil {
+		c.old.remove(entry.old)
+		entry.old = nil
+	} else {
+		c.young.Remove(entry.young)
+		entry.young = nil
+	}
+	delete(c.items, entry.key)
+	c.length -= entry.size
+}
+
+// retain - keep references of evicted entry, the oldest retained history is forgotten
+func (c *ntsLRUK[K, T]) retain(entry *cacheEntryLRUK[K, T]) {
+	if 0 == c.historySize || c.k < 2 {
+		return
+	}
+	c.history.Enqueue(cacheEntryHistory[K]{key: entry.key, hist: entry.hist[:c.k-1]})
+	c.itemsHistory[entry.key] = c.history.Tail()
+	for uint64(c.history.Len()) > c.historySize {
+		c.removeHistory(c.history.Head())
+	}
+}
+
+func (c *ntsLRUK[K, T]) removeHistory(h *list.Node[cacheEntryHistory[K]]) {
+	c.history.Remove(h)
+	delete(c.itemsHistory, h.Value().key)
+}
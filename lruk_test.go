@@ -0,0 +1,158 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsLRUK struct {
+	suite.Suite
+	cache *ntsLRUK[string, int]
+}
+
+func TestNtsLRUK(t *testing.T) {
+	suite.Run(t, new(suiteNtsLRUK))
+}
+
+func (s *suiteNtsLRUK) SetupTest() {
+	//LRU-2 without correlated period
+	s.cache = newNtsLRUK[string, int](2, 3, 10, 0, nil)
+	s.cache.put("1", 1)
+	s.cache.put("2", 2)
+	s.cache.put("3", 3)
+}
+
+func (s *suiteNtsLRUK) TestEvictYoungFirst() {
+	s.cache.get("1", 0)
+	s.Equal([]uint64{4, 1}, s.cache.items["1"].hist)
+	s.Equal(1, s.cache.old.len())
+	s.Equal(2, s.cache.young.Len())
+
+	s.cache.put("4", 4)
+	//2 has one reference and the oldest last reference
+	_, ok := s.cache.get("2", 0)
+	s.False(ok)
+	_, ok = s.cache.get("1", 0)
+	s.True(ok)
+	s.Contains(s.cache.itemsHistory, "2")
+	s.Equal(uint64(3), s.cache.length)
+}
+
+func (s *suiteNtsLRUK) TestKthReference() {
+	for _, k := range []string{"2", "3", "1"} {
+		s.cache.get(k, 0)
+	}
+	s.cache.put("4", 4)
+	//1 has the most recent reference, but the oldest second reference
+	_, ok := s.cache.get("1", 0)
+	s.False(ok)
+	for _, k := range []string{"2", "3", "4"} {
+		_, ok = s.cache.get(k, 0)
+		s.True(ok, k)
+	}
+}
+
+func (s *suiteNtsLRUK) TestScanResistance() {
+	for _, k := range []string{"1", "2"} {
+		s.cache.get(k, 0)
+	}
+	for i := 10; i < 100; i++ {
+		k := strconv.Itoa(i)
+		if _, ok := s.cache.get(k, 0); !ok {
+			s.cache.put(k, i)
+		}
+	}
+	for _, k := range []string{"1", "2"} {
+		_, ok := s.cache.get(k, 0)
+		s.True(ok, k)
+	}
+}
+
+func (s *suiteNtsLRUK) TestRetainedHistory() {
+	s.cache.put("4", 4)
+	_, ok := s.cache.get("1", 0)
+	s.False(ok)
+	s.Equal([]uint64{1}, s.cache.itemsHistory["1"].Value().hist)
+
+	//returned key has two references and 2 with one reference is evicted
+	s.cache.put("1", 1)
+	s.NotContains(s.cache.itemsHistory, "1")
+	s.Equal([]uint64{6, 1}, s.cache.items["1"].hist)
+	_, ok = s.cache.get("2", 0)
+	s.False(ok)
+
+	c := newNtsLRUK[string, int](2, 1, 2, 0, nil)
+	for i := 0; i < 5; i++ {
+		c.put(strconv.Itoa(i), i)
+	}
+	s.Equal(2, c.history.Len())
+	s.Equal([]string{"2", "3"}, []string{c.history.Head().Value().key, c.history.Tail().Value().key})
+}
+
+func (s *suiteNtsLRUK) TestCorrelatedPeriod() {
+	c := newNtsLRUK[string, int](2, 3, 10, 1, nil)
+	c.put("a", 1)
+	//correlated reference isn't counted
+	c.get("a", 0)
+	s.Equal([]uint64{1, 0}, c.items["a"].hist)
+	s.Equal(uint64(2), c.items["a"].last)
+
+	//uncorrelated reference, correlated period of the previous one is excluded from history
+	c.put("b", 2)
+	c.get("a", 0)
+	s.Equal([]uint64{4, 2}, c.items["a"].hist)
+}
+
+func (s *suiteNtsLRUK) TestCorrelatedNotEvicted() {
+	c := newNtsLRUK[string, int](2, 2, 10, 1, nil)
+	c.put("a", 1)
+	c.put("b", 2)
+	c.get("b", 0)
+	c.get("a", 0)
+	c.put("c", 3)
+	//a has the oldest second reference, but it is in correlated period
+	_, ok := c.get("b", 0)
+	s.False(ok)
+	_, ok = c.get("a", 0)
+	s.True(ok)
+	s.Equal(1, c.old.len())
+	s.Same(c.items["a"], c.old.peek().value)
+}
+
+func (s *suiteNtsLRUK) TestPutAndDelete() {
+	c := newNtsLRUK[string, int](2, 10, 10, 0, func(v int) uint64 { return uint64(v) })
+	c.put("a", 4)
+	c.put("b", 4)
+	c.put("big", 11)
+	_, ok := c.get("big", 0)
+	s.False(ok)
+
+	c.put("a", 6)
+	s.Equal(uint64(10), c.length)
+	r, ok := c.get("a", 0)
+	s.True(ok)
+	s.Equal(6, r)
+
+	c.delete("a")
+	_, ok = c.get("a", 0)
+	s.False(ok)
+	s.Equal(uint64(4), c.length)
+	s.Equal(0, c.old.len())
+	s.Equal(1, c.young.Len())
+}
+
+func (s *suiteNtsLRUK) TestTSVersion() {
+	c := NewLRUK[int, int](2, 3, 3, 0, nil)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
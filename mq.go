@@ -2,13 +2,15 @@ package allcache
 
 import (
 	"github.com/satmaelstorm/list"
-	"math"
-	"sync"
+	"math/bits"
 )
 
+// mqAdaptSamples - lifeTime of adaptive MQ is recalculated after this number of ghost hits
+const mqAdaptSamples = 100
+
 type MQ[K comparable, T any] struct {
-	cache *ntsMqCache[K, T]
-	lock  sync.Mutex
+	*Store[K, T]
+	policy *ntsMqCache[K]
 }
 
 func NewMQCache[K comparable, T any](
@@ -17,88 +19,118 @@ func NewMQCache[K comparable, T any](
 	calcQueueNum QueuesNumCalculator,
 	calcSize SizeCalculator[T],
 ) Cache[K, T] {
-	c := new(MQ[K, T])
-	c.cache = newNtsMqCache[K, T](queues, maxSize, qOutSize, lifeTime, calcQueueNum, calcSize)
-	return c
+	policy := newNtsMqCache[K](queues, qOutSize, lifeTime, calcQueueNum)
+	return &MQ[K, T]{Store: NewStore[K, T](policy, maxSize, calcSize), policy: policy}
 }
 
-func (c *MQ[K, T]) Put(key K, item T) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.cache.put(key, item)
+// NewAdaptiveMQCache - MQ which starts with lifeTime and sets it from the distribution of temporal distances,
+// as the MQ paper suggests: distances of hits in qOut are counted in buckets of powers of two, and every
+// 100 hits lifeTime becomes the upper bound of the peak bucket, then counters are halved to forget old distances.
+func NewAdaptiveMQCache[K comparable, T any](
+	queues byte,
+	maxSize, qOutSize, lifeTime uint64,
+	calcQueueNum QueuesNumCalculator,
+	calcSize SizeCalculator[T],
+) *MQ[K, T] {
+	policy := newNtsMqCache[K](queues, qOutSize, lifeTime, calcQueueNum)
+	policy.adaptive = true
+	return &MQ[K, T]{Store: NewStore[K, T](policy, maxSize, calcSize), policy: policy}
+}
+
+// NewMQPolicy - MQ policy for Store
+func NewMQPolicy[K comparable](
+	queues byte,
+	qOutSize, lifeTime uint64,
+	calcQueueNum QueuesNumCalculator,
+) Policy[K] {
+	return newNtsMqCache[K](queues, qOutSize, lifeTime, calcQueueNum)
+}
+
+// NewAdaptiveMQPolicy - MQ policy for Store with adaptive lifeTime, @see NewAdaptiveMQCache
+func NewAdaptiveMQPolicy[K comparable](
+	queues byte,
+	qOutSize, lifeTime uint64,
+	calcQueueNum QueuesNumCalculator,
+) Policy[K] {
+	policy := newNtsMqCache[K](queues, qOutSize, lifeTime, calcQueueNum)
+	policy.adaptive = true
+	return policy
 }
 
-func (c *MQ[K, T]) Get(key K, def T) (T, bool) {
+// LifeTime - current lifeTime, it changes in adaptive mode only
+func (c *MQ[K, T]) LifeTime() uint64 {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	return c.cache.get(key, def)
+	return c.policy.lifeTime
 }
 
-func (c *MQ[K, T]) Delete(key K) {
+// ReuseDistances - histogram of temporal distances of hits in qOut, bucket i counts distances
+// in [2^i, 2^(i+1)) with halving after every recalculation of lifeTime. It is nil if MQ is not adaptive.
+func (c *MQ[K, T]) ReuseDistances() []uint64 {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	c.cache.delete(key)
+	if !c.policy.adaptive {
+		return nil
+	}
+	r := make([]uint64, len(c.policy.distances))
+	copy(r, c.policy.distances[:])
+	return r
 }
 
-type ntsMqCache[K comparable, T any] struct {
-	q     []*list.Queue[cacheEntryMQ[K, T]]
-	items map[K]*list.Node[cacheEntryMQ[K, T]]
+type ntsMqCache[K comparable] struct {
+	heldKeys[K]
+	q     []*list.Queue[*cacheEntryMQ[K]]
+	items map[K]*list.Node[*cacheEntryMQ[K]]
 
 	qOut     *list.Queue[cacheEntryOutMQ[K]]
 	itemsOut map[K]*list.Node[cacheEntryOutMQ[K]]
 
 	queues   byte
-	maxSize  uint64
 	qOutSize uint64
 	lifeTime uint64
 
-	calcSize     SizeCalculator[T]
 	calcQueueNum QueuesNumCalculator
 
 	currentTime uint64
-	currentSize uint64
+
+	// adaptive - lifeTime is the peak of distances of qOut hits
+	adaptive  bool
+	distances [64]uint64
+	samples   uint64
 }
 
-func newNtsMqCache[K comparable, T any](
+func newNtsMqCache[K comparable](
 	queues byte,
-	maxSize, qOutSize, lifeTime uint64,
+	qOutSize, lifeTime uint64,
 	calcQueueNum QueuesNumCalculator,
-	calcSize SizeCalculator[T],
-) *ntsMqCache[K, T] {
-	if nil == calcSize {
-		calcSize = func(T) uint64 { return 1 }
-	}
+) *ntsMqCache[K] {
 	if nil == calcQueueNum {
-		calcQueueNum = func(hits uint64) byte {
-			if hits < 1 {
-				return 0
-			}
-			r := math.Log2(float64(hits))
-			return byte(r)
-		}
+		calcQueueNum = Log2QueuesNum
+	}
+	if queues < 1 {
+		queues = 1
 	}
-	qs := make([]*list.Queue[cacheEntryMQ[K, T]], queues)
+	qs := make([]*list.Queue[*cacheEntryMQ[K]], queues)
 	for i := byte(0); i < queues; i++ {
-		qs[i] = list.NewQueue[cacheEntryMQ[K, T]]()
+		qs[i] = list.NewQueue[*cacheEntryMQ[K]]()
 	}
-	return &ntsMqCache[K, T]{
-		items: make(map[K]*list.Node[cacheEntryMQ[K, T]], maxSize),
-		q:     qs,
+	return &ntsMqCache[K]{
+		heldKeys: make(heldKeys[K]),
+		items:    make(map[K]*list.Node[*cacheEntryMQ[K]]),
+		q:        qs,
 
 		qOut:     list.NewQueue[cacheEntryOutMQ[K]](),
 		itemsOut: make(map[K]*list.Node[cacheEntryOutMQ[K]]),
 
 		queues:   queues,
-		maxSize:  maxSize,
 		qOutSize: qOutSize,
 		lifeTime: lifeTime,
 
-		calcSize:     calcSize,
 		calcQueueNum: calcQueueNum,
 	}
 }
 
-func (c *ntsMqCache[K, T]) adjust() {
+func (c *ntsMqCache[K]) adjust() {
 	c.currentTime += 1
 	for k := byte(1); k < c.queues; k++ {
 		e := c.q[k].Head()
@@ -106,137 +138,136 @@ func (c *ntsMqCache[K, T]) adjust() {
 			continue
 		}
 		if e.Value().expire < c.currentTime {
-			e = c.q[k].Dequeue()
+			c.q[k].Remove(e)
 			entry := e.Value()
 			entry.expire = c.expire()
 			entry.qNum = k - 1
 			c.q[k-1].Enqueue(entry)
+			c.items[entry.key] = c.q[k-1].Tail()
 		}
 	}
 }
 
-func (c *ntsMqCache[K, T]) evict() {
-	for k := byte(0); k < c.queues; k++ {
-		victim := c.q[k].Dequeue()
-		if nil == victim {
-			continue
-		}
-		key := victim.Value().key
-		delete(c.items, key)
-		if uint64(c.qOut.Len()) > c.qOutSize {
-			drop := c.qOut.Dequeue()
-			if drop != nil {
-				delete(c.itemsOut, drop.Value().key)
-			}
-		}
-		entry := cacheEntryOutMQ[K]{key: key, hits: victim.Value().hits}
-		c.qOut.Enqueue(entry)
-		c.itemsOut[key] = c.qOut.Tail()
-		size := c.calcSize(victim.Value().value)
-		if size > c.currentSize {
-			panic("MqCache current size less than size of evicted element")
-		}
-		c.currentSize -= size
-		break
+// OnInsert - new key gets hits remembered in qOut, replaced value is a hit
+func (c *ntsMqCache[K]) OnInsert(key K, _ uint64) {
+	if _, ok := c.items[key]; ok {
+		c.OnAccess(key)
+		return
 	}
-}
-
-func (c *ntsMqCache[K, T]) delete(key K) {
-	if e, ok := c.items[key]; ok {
-		delete(c.items, key)
-		c.q[e.Value().qNum].Remove(e)
-		size := c.calcSize(e.Value().value)
-		if size > c.currentSize {
-			panic("MqCache current size less than size of deleted element")
-		}
-		c.currentSize -= size
-	}
-	if e, ok := c.itemsOut[key]; ok {
+	defer c.adjust()
+	entry := &cacheEntryMQ[K]{key: key}
+	if k, ok := c.itemsOut[key]; ok {
 		delete(c.itemsOut, key)
-		c.qOut.Remove(e)
+		c.qOut.Remove(k)
+		entry.hits = k.Value().hits
+		c.recordDistance(c.currentTime - k.Value().last)
 	}
+	entry.hits += 1
+	entry.qNum = c.queueNum(entry.hits)
+	entry.expire = c.expire()
+	entry.last = c.currentTime
+	c.q[entry.qNum].Enqueue(entry)
+	c.items[key] = c.q[entry.qNum].Tail()
 }
 
-func (c *ntsMqCache[K, T]) put(key K, value T) {
+func (c *ntsMqCache[K]) OnAccess(key K) {
 	defer c.adjust()
-	var curItem cacheEntryMQ[K, T]
-	curSize := c.calcSize(value)
-	if curSize > c.maxSize {
-		panic("MqCache put element larger than cache size")
-	}
 	e, ok := c.items[key]
-	checkMove := false
-	curQ := byte(0)
-	if ok {
-		curItem = e.Value()
-		curQ = curItem.qNum
-		checkMove = true
+	if !ok {
+		return
+	}
+	entry := e.Value()
+	curQ := entry.qNum
+	entry.hits += 1
+	entry.qNum = c.queueNum(entry.hits)
+	entry.expire = c.expire()
+	entry.last = c.currentTime
+	if curQ != entry.qNum {
+		c.q[curQ].Remove(e)
+		c.q[entry.qNum].Enqueue(entry)
+		c.items[key] = c.q[entry.qNum].Tail()
 	} else {
-		curItem.key = key
-		if k, ok := c.itemsOut[key]; ok {
+		c.q[entry.qNum].MoveToBack(e)
+	}
+}
+
+// OnRemove - hits of evicted key are remembered in qOut
+func (c *ntsMqCache[K]) OnRemove(key K, reason RemoveReason) {
+	e, ok := c.items[key]
+	if !ok {
+		if g, ok := c.itemsOut[key]; ok && RemoveDeleted == reason {
 			delete(c.itemsOut, key)
-			c.qOut.Remove(k)
-			curItem.hits = k.Value().hits
+			c.qOut.Remove(g)
 		}
+		return
 	}
-
-	for (curSize + c.currentSize) > c.maxSize {
-		c.evict()
+	delete(c.items, key)
+	delete(c.heldKeys, key)
+	c.q[e.Value().qNum].Remove(e)
+	if reason != RemoveEvicted {
+		return
 	}
-
-	curItem.hits += 1
-	curItem.value = value
-	curItem.qNum = c.queueNum(curItem.hits)
-	curItem.expire = c.expire()
-
-	c.currentSize += curSize
-
-	if checkMove {
-		if curQ != curItem.qNum {
-			c.q[curQ].Remove(e)
-		} else {
-			e.SetValue(curItem)
-			c.q[curItem.qNum].MoveToBack(e)
-			return
-		}
+	if uint64(c.qOut.Len()) > c.qOutSize {
+		drop := c.qOut.Head()
+		c.qOut.Remove(drop)
+		delete(c.itemsOut, drop.Value().key)
 	}
-
-	c.q[curItem.qNum].Enqueue(curItem)
-	c.items[key] = c.q[curItem.qNum].Tail()
+	c.qOut.Enqueue(cacheEntryOutMQ[K]{key: key, hits: e.Value().hits, last: e.Value().last})
+	c.itemsOut[key] = c.qOut.Tail()
 }
 
-func (c *ntsMqCache[K, T]) get(key K, def T) (T, bool) {
-	defer c.adjust()
-	e, ok := c.items[key]
-	if ok {
-		curItem := e.Value()
-		curQ := curItem.qNum
-		curItem.hits += 1
-		curItem.qNum = c.queueNum(curItem.hits)
-		curItem.expire = c.expire()
-		if curQ != curItem.qNum {
-			c.q[curQ].Remove(e)
-			c.q[curItem.qNum].Enqueue(curItem)
-			c.items[key] = c.q[curItem.qNum].Tail()
-		} else {
-			e.SetValue(curItem)
-			c.q[curItem.qNum].MoveToBack(e)
+// Victim - head of the lowest non empty queue, held keys are skipped
+func (c *ntsMqCache[K]) Victim() (K, bool) {
+	for k := byte(0); k < c.queues; k++ {
+		for e := c.q[k].Head(); e != nil; e = e.Next() {
+			if !c.isHeld(e.Value().key) {
+				return e.Value().key, true
+			}
 		}
-		return curItem.value, true
 	}
-	return def, false
+	var zero K
+	return zero, false
 }
 
-func (c *ntsMqCache[K, T]) queueNum(hits uint64) byte {
+func (c *ntsMqCache[K]) queueNum(hits uint64) byte {
 	qn := c.calcQueueNum(hits)
-	if qn < 0 {
-		qn = 0
-	} else if qn > c.queues {
-		qn = c.queues
+	if qn >= c.queues {
+		qn = c.queues - 1
 	}
 	return qn
 }
 
-func (c *ntsMqCache[K, T]) expire() uint64 {
+func (c *ntsMqCache[K]) expire() uint64 {
 	return c.currentTime + c.lifeTime
 }
+
+// recordDistance - count temporal distance of qOut hit and recalculate lifeTime in adaptive mode
+func (c *ntsMqCache[K]) recordDistance(distance uint64) {
+	if !c.adaptive {
+		return
+	}
+	b := 0
+	if distance > 0 {
+		b = bits.Len64(distance) - 1
+	}
+	c.distances[b] += 1
+	c.samples += 1
+	if c.samples < mqAdaptSamples {
+		return
+	}
+	c.samples = 0
+	peak := 0
+	for i := range c.distances {
+		if c.distances[i] > c.distances[peak] {
+			peak = i
+		}
+	}
+	for i := range c.distances {
+		c.distances[i] /= 2
+	}
+	//the last bucket is never reached by distances, but expire must not overflow
+	if peak > 62 {
+		peak = 62
+	}
+	c.lifeTime = 1 << (peak + 1)
+}
@@ -8,7 +8,8 @@ import (
 
 type suiteNtsMqCache struct {
 	suite.Suite
-	cache *ntsMqCache[string, int]
+	cache  *ntsStore[string, int]
+	policy *ntsMqCache[string]
 }
 
 func TestMqCache(t *testing.T) {
@@ -16,7 +17,7 @@ func TestMqCache(t *testing.T) {
 }
 
 func (s *suiteNtsMqCache) SetupTest() {
-	s.cache = newNtsMqCache[string, int](8, 5, 5, 5, func(hits uint64) byte {
+	s.policy = newNtsMqCache[string](8, 5, 5, func(hits uint64) byte {
 		if hits < 2 {
 			return 0
 		}
@@ -24,7 +25,8 @@ func (s *suiteNtsMqCache) SetupTest() {
 			return 8
 		}
 		return byte(hits - 1)
-	}, nil)
+	})
+	s.cache = newNtsStore[string, int](s.policy, 5, nil)
 
 	for i := 1; i <= 10; i++ {
 		s.cache.put(strconv.Itoa(i), i)
@@ -44,6 +46,34 @@ func (s *suiteNtsMqCache) TestFill() {
 	}
 }
 
+func (s *suiteNtsMqCache) TestTopQueue() {
+	for i := 0; i < 20; i++ {
+		r, ok := s.cache.get("10", 0)
+		s.True(ok)
+		s.Equal(10, r)
+	}
+	s.Equal(byte(7), s.policy.items["10"].Value().qNum)
+}
+
+func (s *suiteNtsMqCache) TestDemote() {
+	s.cache.get("10", 0)
+	s.cache.get("10", 0)
+	s.Equal(byte(2), s.policy.items["10"].Value().qNum)
+	for i := 0; i < 10; i++ {
+		s.cache.get("9", 0)
+	}
+	e := s.policy.items["10"]
+	s.Equal(byte(1), e.Value().qNum)
+	s.Equal(e, s.policy.q[1].Tail())
+
+	for i := 11; i <= 20; i++ {
+		s.cache.put(strconv.Itoa(i), i)
+	}
+	s.Equal(uint64(5), s.cache.weight)
+	s.Len(s.cache.items, 5)
+	s.Len(s.policy.items, 5)
+}
+
 func (s *suiteNtsMqCache) TestTSVersion() {
 	c := NewMQCache[int, int](8, 5, 5, 5, nil, nil)
 	c.Put(1, 1)
@@ -58,3 +88,47 @@ func (s *suiteNtsMqCache) TestTSVersion() {
 	s.False(ok)
 	s.Equal(0, r)
 }
+
+func (s *suiteNtsMqCache) TestAdaptiveLifeTime() {
+	c := newNtsMqCache[string](4, 100, 5, nil)
+	c.adaptive = true
+	for i := 0; i < mqAdaptSamples-2; i++ {
+		c.recordDistance(uint64(20 + i%10))
+	}
+	c.recordDistance(1000)
+	s.Equal(uint64(5), c.lifeTime)
+	c.recordDistance(0)
+	//distances 16..31 are the peak
+	s.Equal(uint64(32), c.lifeTime)
+	s.Equal(uint64(49), c.distances[4])
+	s.Equal(uint64(0), c.distances[9])
+	s.Equal(uint64(0), c.samples)
+
+	//not adaptive policy doesn't count distances
+	s.policy.recordDistance(10)
+	s.Equal(uint64(0), s.policy.samples)
+}
+
+func (s *suiteNtsMqCache) TestGhostDistance() {
+	c := NewAdaptiveMQCache[string, int](4, 10, 100, 5, nil, nil)
+	//loop of 15 keys: every key comes back from qOut after 15 requests
+	for i := 0; i < 3000; i++ {
+		k := strconv.Itoa(i % 15)
+		if _, ok := c.Get(k, 0); !ok {
+			c.Put(k, i)
+		}
+	}
+	s.Equal(uint64(16), c.LifeTime())
+	d := c.ReuseDistances()
+	s.Len(d, 64)
+	s.Greater(d[3], uint64(0))
+	for i, n := range d {
+		if i != 3 {
+			s.Equal(uint64(0), n)
+		}
+	}
+
+	mq := NewMQCache[int, int](8, 5, 5, 5, nil, nil).(*MQ[int, int])
+	s.Equal(uint64(5), mq.LifeTime())
+	s.Nil(mq.ReuseDistances())
+}
@@ -0,0 +1,141 @@
+package mrc
+
+import (
+	"io"
+	"sort"
+
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+// fenwick - binary indexed tree over access times, grows on demand
+type fenwick struct {
+	tree []int32
+}
+
+func (f *fenwick) add(i int, v int32) {
+	for ; i < len(f.tree); i += i & -i {
+		f.tree[i] += v
+	}
+}
+
+func (f *fenwick) sum(i int) int32 {
+	r := int32(0)
+	for ; i > 0; i -= i & -i {
+		r += f.tree[i]
+	}
+	return r
+}
+
+func (f *fenwick) grow(size int) {
+	if size < len(f.tree) {
+		return
+	}
+	n := 2 * len(f.tree)
+	if n <= size {
+		n = size + 1
+	}
+	values := make([]int32, n)
+	for i := 1; i < len(f.tree); i++ {
+		values[i] = f.sum(i) - f.sum(i-1)
+	}
+	for i := 1; i < n; i++ {
+		j := i + (i & -i)
+		if j < n {
+			values[j] += values[i]
+		}
+	}
+	f.tree = values
+}
+
+// LRU - exact miss ratio curve of LRU for every capacity in one pass over the trace.
+// Stack distance of every request is counted with Fenwick tree in O(log n).
+type LRU struct {
+	last     map[string]int
+	times    fenwick
+	now      int
+	requests uint64
+	// hist[d] - number of requests with stack distance d, first requests of keys are not counted
+	hist []uint64
+}
+
+func NewLRU() *LRU {
+	return &LRU{last: make(map[string]int), times: fenwick{tree: make([]int32, 1024)}}
+}
+
+// Access - process one request, delete requests forget the key
+func (l *LRU) Access(req trace.Request) {
+	prev, seen := l.last[req.Key]
+	if trace.OpDelete == req.Op {
+		if seen {
+			l.times.add(prev, -1)
+			delete(l.last, req.Key)
+		}
+		return
+	}
+	l.requests += 1
+	l.now += 1
+	l.times.grow(l.now)
+	if seen {
+		d := uint64(l.times.sum(l.now-1) - l.times.sum(prev) + 1)
+		for uint64(len(l.hist)) <= d {
+			l.hist = append(l.hist, 0)
+		}
+		l.hist[d] += 1
+		l.times.add(prev, -1)
+	}
+	l.times.add(l.now, 1)
+	l.last[req.Key] = l.now
+}
+
+// ReadFrom - process all requests of the reader
+func (l *LRU) ReadFrom(r trace.Reader) error {
+	for {
+		req, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		l.Access(req)
+	}
+}
+
+// MissRatio - miss ratio of LRU with given capacity in items
+func (l *LRU) MissRatio(capacity uint64) float64 {
+	return l.Curve([]uint64{capacity}).Points[0].MissRatio
+}
+
+// Curve - miss ratios for given capacities
+func (l *LRU) Curve(capacities []uint64) Curve {
+	sorted := append([]uint64(nil), capacities...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	result := Curve{Policy: "lru", Points: make([]Point, 0, len(sorted))}
+	hits := uint64(0)
+	d := uint64(1)
+	for _, c := range sorted {
+		for ; d <= c && d < uint64(len(l.hist)); d++ {
+			hits += l.hist[d]
+		}
+		result.Points = append(result.Points, Point{Capacity: c, MissRatio: l.missRatio(hits)})
+	}
+	return result
+}
+
+// Full - miss ratio for every capacity where it changes, up to the number of distinct keys
+func (l *LRU) Full() Curve {
+	var capacities []uint64
+	for d := 1; d < len(l.hist); d++ {
+		if l.hist[d] > 0 {
+			capacities = append(capacities, uint64(d))
+		}
+	}
+	return l.Curve(capacities)
+}
+
+func (l *LRU) missRatio(hits uint64) float64 {
+	if 0 == l.requests {
+		return 0
+	}
+	return 1 - float64(hits)/float64(l.requests)
+}
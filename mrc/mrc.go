@@ -0,0 +1,41 @@
+// Package mrc builds miss ratio curves: miss ratio of the cache as function of its capacity.
+package mrc
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+type Point struct {
+	Capacity  uint64
+	MissRatio float64
+}
+
+// Curve - miss ratio curve of one policy, points are sorted by capacity
+type Curve struct {
+	Policy string
+	Points []Point
+}
+
+// WriteCSV - write curves as "policy,capacity,miss_ratio" CSV with header
+func WriteCSV(w io.Writer, curves ...Curve) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"policy", "capacity", "miss_ratio"}); err != nil {
+		return err
+	}
+	for _, c := range curves {
+		for _, p := range c.Points {
+			record := []string{
+				c.Policy,
+				strconv.FormatUint(p.Capacity, 10),
+				strconv.FormatFloat(p.MissRatio, 'f', 6, 64),
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,113 @@
+package mrc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/satmaelstorm/allcache/sim"
+	"github.com/satmaelstorm/allcache/trace"
+	"github.com/satmaelstorm/allcache/workload"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteMrc struct {
+	suite.Suite
+	requests []trace.Request
+}
+
+func TestMrc(t *testing.T) {
+	suite.Run(t, new(suiteMrc))
+}
+
+func (s *suiteMrc) SetupSuite() {
+	requests, err := trace.ReadAll(workload.Reader(workload.NewZipf(1, 5000, 0.9), 50000))
+	s.Require().NoError(err)
+	s.requests = requests
+}
+
+func (s *suiteMrc) TestLRUExact() {
+	l := NewLRU()
+	for _, r := range s.requests {
+		l.Access(r)
+	}
+	lru, err := sim.PolicyByName("lru")
+	s.Require().NoError(err)
+
+	capacities := []uint64{1000, 10, 250, 50}
+	curve := l.Curve(capacities)
+	s.Equal("lru", curve.Policy)
+	s.Len(curve.Points, 4)
+	prev := 1.0
+	for _, p := range curve.Points {
+		r, err := sim.Replay(s.requests, lru, p.Capacity, false)
+		s.Require().NoError(err)
+		s.InDelta(1-r.HitRatio(), p.MissRatio, 1e-12, p.Capacity)
+		s.LessOrEqual(p.MissRatio, prev)
+		prev = p.MissRatio
+	}
+	s.Equal(uint64(10), curve.Points[0].Capacity)
+
+	full := l.Full()
+	s.NotEmpty(full.Points)
+	last := full.Points[len(full.Points)-1]
+	s.InDelta(l.MissRatio(last.Capacity*2), last.MissRatio, 1e-12)
+}
+
+func (s *suiteMrc) TestLRUDelete() {
+	l := NewLRU()
+	r := trace.NewLIRSReader(bytes.NewBufferString("a\nb\na\nb\n"))
+	s.Require().NoError(l.ReadFrom(r))
+	s.InDelta(0.5, l.MissRatio(2), 1e-12)
+	s.InDelta(1, l.MissRatio(1), 1e-12)
+
+	l.Access(trace.Request{Key: "a", Op: trace.OpDelete})
+	l.Access(trace.Request{Key: "a"})
+	l.Access(trace.Request{Key: "b"})
+	s.InDelta(0.5, l.MissRatio(2), 1e-12)
+}
+
+func (s *suiteMrc) TestSampled() {
+	_, err := NewSampler(0)
+	s.ErrorIs(err, ErrBadRate)
+
+	all, err := NewSampler(1)
+	s.Require().NoError(err)
+	lru, err := sim.PolicyByName("lru")
+	s.Require().NoError(err)
+	curve, err := Sampled(s.requests, all, lru, []uint64{100}, false)
+	s.Require().NoError(err)
+	r, err := sim.Replay(s.requests, lru, 100, false)
+	s.Require().NoError(err)
+	s.InDelta(1-r.HitRatio(), curve.Points[0].MissRatio, 1e-12)
+
+	// spatial sampling needs large key space
+	sampler, err := NewSampler(0.1)
+	s.Require().NoError(err)
+	sampled, err := sampler.Sample(workload.Reader(workload.NewZipf(2, 100000, 0.8), 300000))
+	s.Require().NoError(err)
+	s.InDelta(30000, len(sampled), 6000)
+
+	exact := NewLRU()
+	s.Require().NoError(exact.ReadFrom(workload.Reader(workload.NewZipf(2, 100000, 0.8), 300000)))
+	capacities := []uint64{2000, 5000, 10000, 20000}
+	approx, err := Sampled(sampled, sampler, lru, capacities, false)
+	s.Require().NoError(err)
+	for i, p := range exact.Curve(capacities).Points {
+		s.InDelta(p.MissRatio, approx.Points[i].MissRatio, 0.03, p.Capacity)
+	}
+
+	for _, name := range []string{"2q", "mq", "lfu"} {
+		p, err := sim.PolicyByName(name)
+		s.Require().NoError(err)
+		c, err := Sampled(sampled, sampler, p, capacities, false)
+		s.Require().NoError(err)
+		s.Len(c.Points, len(capacities))
+		s.Equal(name, c.Policy)
+	}
+}
+
+func (s *suiteMrc) TestWriteCSV() {
+	buf := new(bytes.Buffer)
+	s.NoError(WriteCSV(buf, Curve{Policy: "lru", Points: []Point{{Capacity: 10, MissRatio: 0.5}}}))
+	s.Equal("policy,capacity,miss_ratio\nlru,10,0.500000\n", buf.String())
+}
@@ -0,0 +1,103 @@
+package mrc
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/satmaelstorm/allcache"
+	"github.com/satmaelstorm/allcache/sim"
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+const shardsModulus = 1 << 24
+
+var ErrBadRate = errors.New("sampling rate must be in (0, 1]")
+
+// Sampler - SHARDS spatial sampling: request is sampled if hash of its key is below threshold,
+// so all requests of sampled key are kept and key set is reduced by rate
+// @see https://www.usenix.org/conference/fast15/technical-sessions/presentation/waldspurger
+type Sampler struct {
+	threshold uint64
+	rate      float64
+	// total - requests seen by Sample, sampled or not
+	total uint64
+}
+
+func NewSampler(rate float64) (*Sampler, error) {
+	if rate <= 0 || rate > 1 {
+		return nil, ErrBadRate
+	}
+	return &Sampler{threshold: uint64(math.Round(rate * shardsModulus)), rate: rate}, nil
+}
+
+func (s *Sampler) Sampled(key string) bool {
+	return allcache.HashKey(key)%shardsModulus < s.threshold
+}
+
+// Scale - capacity of miniature cache which models cache with given capacity, at least 1
+func (s *Sampler) Scale(capacity uint64) uint64 {
+	r := uint64(math.Round(float64(capacity) * s.rate))
+	if r < 1 {
+		return 1
+	}
+	return r
+}
+
+// Sample - read all requests of sampled keys into memory
+func (s *Sampler) Sample(r trace.Reader) ([]trace.Request, error) {
+	var result []trace.Request
+	for {
+		req, err := r.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		if s.Observe(req) {
+			result = append(result, req)
+		}
+	}
+}
+
+// Observe - count request for SHARDS-adj and report whether it is sampled
+func (s *Sampler) Observe(req trace.Request) bool {
+	if req.Op != trace.OpDelete {
+		s.total += 1
+	}
+	return s.Sampled(req.Key)
+}
+
+// adjust - SHARDS-adj: misses are divided by expected number of sampled requests instead of actual,
+// it removes bias of frequently requested keys which are sampled or not
+func (s *Sampler) adjust(r sim.Result) float64 {
+	if 0 == s.total {
+		return 1 - r.HitRatio()
+	}
+	expected := float64(s.total) * s.rate
+	missRatio := float64(r.Requests-r.Hits) / expected
+	if missRatio > 1 {
+		return 1
+	}
+	return missRatio
+}
+
+// Sampled - approximate miss ratio curve of any policy with miniature simulations:
+// sampled requests are replayed against the policy with capacities scaled by sampling rate.
+// If requests were sampled by sampler.Sample, SHARDS-adj correction is applied.
+// If weighted is true capacities are in bytes.
+func Sampled(requests []trace.Request, sampler *Sampler, policy sim.Policy, capacities []uint64, weighted bool) (Curve, error) {
+	sorted := append([]uint64(nil), capacities...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	result := Curve{Policy: policy.Name, Points: make([]Point, 0, len(sorted))}
+	for _, c := range sorted {
+		r, err := sim.Replay(requests, policy, sampler.Scale(c), weighted)
+		if err != nil {
+			return result, err
+		}
+		result.Points = append(result.Points, Point{Capacity: c, MissRatio: sampler.adjust(r)})
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package allcache
+
+import (
+	"sync"
+	"time"
+)
+
+// LookupResult - result of NegativeCache.Lookup
+type LookupResult byte
+
+const (
+	// LookupMiss - nothing is known about the key
+	LookupMiss LookupResult = iota
+	// LookupHit - value of the key is cached
+	LookupHit
+	// LookupAbsent - the key is known to be absent in the source
+	LookupAbsent
+)
+
+func (r LookupResult) String() string {
+	switch r {
+	case LookupMiss:
+		return "miss"
+	case LookupHit:
+		return "hit"
+	case LookupAbsent:
+		return "absent"
+	}
+	return "unknown"
+}
+
+// NegativeCache - any cache with negative entries for keys which are absent in the source.
+// Negative entries are kept by their own LRU store of maxAbsent keys, so they never push values out of the cache.
+type NegativeCache[K comparable, T any] struct {
+	cache  Cache[K, T]
+	absent *Store[K, struct{}]
+	// lock - orders writes of the key to both caches, reads need no lock
+	lock sync.Mutex
+}
+
+func NewNegativeCache[K comparable, T any](cache Cache[K, T], maxAbsent uint64) *NegativeCache[K, T] {
+	return &NegativeCache[K, T]{
+		cache:  cache,
+		absent: NewStore[K, struct{}](NewLRUPolicy[K](), maxAbsent, nil),
+	}
+}
+
+// Put - put value of the key, negative entry of the key is deleted
+func (c *NegativeCache[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.absent.Delete(key)
+	c.cache.Put(key, item)
+}
+
+// PutAbsent - remember that the key is absent for ttl, 0 means until it is evicted. Value of the key is deleted.
+func (c *NegativeCache[K, T]) PutAbsent(key K, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.Delete(key)
+	c.absent.PutWithTTL(key, struct{}{}, ttl)
+}
+
+// Get - value of the key, known absent key is a miss
+func (c *NegativeCache[K, T]) Get(key K, def T) (T, bool) {
+	return c.cache.Get(key, def)
+}
+
+// Lookup - value of the key with LookupHit, def with LookupAbsent or LookupMiss
+func (c *NegativeCache[K, T]) Lookup(key K, def T) (T, LookupResult) {
+	if r, ok := c.cache.Get(key, def); ok {
+		return r, LookupHit
+	}
+	if _, ok := c.absent.Get(key, struct{}{}); ok {
+		return def, LookupAbsent
+	}
+	return def, LookupMiss
+}
+
+// Delete - delete value and negative entry of the key
+func (c *NegativeCache[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.absent.Delete(key)
+	c.cache.Delete(key)
+}
+
+// DeleteFunc - delete values for which f returns true and negative entries for which f returns true
+// with zero value. Values are deleted only if the underlying cache is BulkDeleter, all caches of the package are.
+func (c *NegativeCache[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var zero T
+	n := c.absent.DeleteFunc(func(key K, _ struct{}) bool {
+		return f(key, zero)
+	})
+	if d, ok := c.cache.(BulkDeleter[K, T]); ok {
+		n += d.DeleteFunc(f)
+	}
+	return n
+}
+
+// AbsentLen - number of negative entries, expired entries are counted until they are removed
+func (c *NegativeCache[K, T]) AbsentLen() int {
+	return c.absent.Len()
+}
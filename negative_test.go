@@ -0,0 +1,88 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type suiteNegativeCache struct {
+	suite.Suite
+	cache *NegativeCache[string, int]
+	now   time.Time
+}
+
+func TestNegativeCache(t *testing.T) {
+	suite.Run(t, new(suiteNegativeCache))
+}
+
+func (s *suiteNegativeCache) SetupTest() {
+	s.cache = NewNegativeCache[string, int](NewLRU[string, int](3, nil), 2)
+	s.now = time.Unix(1000, 0)
+	s.cache.absent.cache.now = func() time.Time { return s.now }
+}
+
+func (s *suiteNegativeCache) TestLookup() {
+	s.cache.Put("a", 1)
+	s.cache.PutAbsent("b", time.Minute)
+
+	r, res := s.cache.Lookup("a", 0)
+	s.Equal(LookupHit, res)
+	s.Equal(1, r)
+	r, res = s.cache.Lookup("b", -1)
+	s.Equal(LookupAbsent, res)
+	s.Equal(-1, r)
+	_, res = s.cache.Lookup("c", 0)
+	s.Equal(LookupMiss, res)
+	_, ok := s.cache.Get("b", 0)
+	s.False(ok)
+
+	s.now = s.now.Add(time.Minute)
+	_, res = s.cache.Lookup("b", 0)
+	s.Equal(LookupMiss, res)
+	s.Equal("absent", LookupAbsent.String())
+}
+
+func (s *suiteNegativeCache) TestOverride() {
+	s.cache.PutAbsent("a", 0)
+	s.cache.Put("a", 1)
+	_, res := s.cache.Lookup("a", 0)
+	s.Equal(LookupHit, res)
+	s.Equal(0, s.cache.AbsentLen())
+
+	s.cache.PutAbsent("a", 0)
+	_, res = s.cache.Lookup("a", 0)
+	s.Equal(LookupAbsent, res)
+
+	s.cache.Delete("a")
+	_, res = s.cache.Lookup("a", 0)
+	s.Equal(LookupMiss, res)
+}
+
+func (s *suiteNegativeCache) TestBounded() {
+	for _, c := range []Cache[string, int]{
+		NewLRU[string, int](3, nil),
+		NewFull2Q[string, int](2, 1, 3),
+		NewMQCache[string, int](4, 3, 3, 3, nil, nil),
+	} {
+		n := NewNegativeCache[string, int](c, 2)
+		for i := 0; i < 3; i++ {
+			n.Put(strconv.Itoa(i), i)
+		}
+		for i := 0; i < 100; i++ {
+			n.PutAbsent("absent"+strconv.Itoa(i), 0)
+		}
+		//negative entries don't push values out
+		for i := 0; i < 3; i++ {
+			r, res := n.Lookup(strconv.Itoa(i), 0)
+			s.Equal(LookupHit, res)
+			s.Equal(i, r)
+		}
+		s.Equal(2, n.AbsentLen())
+		_, res := n.Lookup("absent99", 0)
+		s.Equal(LookupAbsent, res)
+		_, res = n.Lookup("absent0", 0)
+		s.Equal(LookupMiss, res)
+	}
+}
@@ -0,0 +1,141 @@
+package allcache
+
+import "strings"
+
+// keyIndex - index of keys of Store, it is updated under lock of the store
+type keyIndex[K comparable] interface {
+	insert(key K)
+	remove(key K)
+}
+
+// prefixDeleter - cache which can delete keys with prefix without scan of all keys
+type prefixDeleter interface {
+	deletePrefix(prefix string) (int, bool)
+}
+
+// DeletePrefix - delete items with keys starting with prefix, returns number of deleted items.
+// Store with WithPrefixIndex finds the keys by its radix tree, other caches scan all keys.
+func DeletePrefix[T any](c BulkDeleter[string, T], prefix string) int {
+	if d, ok := c.(prefixDeleter); ok {
+		if n, ok := d.deletePrefix(prefix); ok {
+			return n
+		}
+	}
+	return c.DeleteFunc(func(key string, _ T) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// radixTree - set of strings as compressed trie, edges are labeled by substrings of keys
+type radixTree struct {
+	root radixNode
+}
+
+type radixNode struct {
+	// label - substring of keys between parent and the node
+	label    string
+	children map[byte]*radixNode
+	// isKey - path from the root to the node is a key
+	isKey bool
+}
+
+func newRadixTree() *radixTree {
+	return &radixTree{}
+}
+
+func (t *radixTree) insert(key string) {
+	n := &t.root
+	for {
+		if "" == key {
+			n.isKey = true
+			return
+		}
+		child, ok := n.children[key[0]]
+		if !ok {
+			if nil == n.children {
+				n.children = make(map[byte]*radixNode)
+			}
+			n.children[key[0]] = &radixNode{label: key, isKey: true}
+			return
+		}
+		common := commonPrefixLen(child.label, key)
+		if common < len(child.label) {
+			//split the edge
+			mid := &radixNode{label: child.label[:common], children: map[byte]*radixNode{child.label[common]: child}}
+			child.label = child.label[common:]
+			n.children[key[0]] = mid
+			child = mid
+		}
+		n = child
+		key = key[common:]
+	}
+}
+
+func (t *radixTree) remove(key string) {
+	t.root.remove(key)
+}
+
+// remove - remove the key under the node, merge nodes which are not keys and have the only child
+func (n *radixNode) remove(key string) {
+	if "" == key {
+		n.isKey = false
+		return
+	}
+	child, ok := n.children[key[0]]
+	if !ok || !strings.HasPrefix(key, child.label) {
+		return
+	}
+	child.remove(key[len(child.label):])
+	switch {
+	case child.isKey:
+	case 0 == len(child.children):
+		delete(n.children, key[0])
+	case 1 == len(child.children):
+		for _, grandChild := range child.children {
+			grandChild.label = child.label + grandChild.label
+			n.children[key[0]] = grandChild
+		}
+	}
+}
+
+// keysWithPrefix - all keys starting with prefix
+func (t *radixTree) keysWithPrefix(prefix string) []string {
+	n := &t.root
+	path := ""
+	for "" != prefix {
+		child, ok := n.children[prefix[0]]
+		if !ok {
+			return nil
+		}
+		switch {
+		case strings.HasPrefix(prefix, child.label):
+			prefix = prefix[len(child.label):]
+		case strings.HasPrefix(child.label, prefix):
+			prefix = ""
+		default:
+			return nil
+		}
+		path += child.label
+		n = child
+	}
+	var keys []string
+	n.collect(path, &keys)
+	return keys
+}
+
+func (n *radixNode) collect(path string, keys *[]string) {
+	if n.isKey {
+		*keys = append(*keys, path)
+	}
+	for _, child := range n.children {
+		child.collect(path+child.label, keys)
+	}
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
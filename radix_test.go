@@ -0,0 +1,150 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type suiteDeletePrefix struct {
+	suite.Suite
+}
+
+func TestDeletePrefix(t *testing.T) {
+	suite.Run(t, new(suiteDeletePrefix))
+}
+
+func (s *suiteDeletePrefix) TestRadixTree() {
+	t := newRadixTree()
+	for _, k := range []string{"tenant:42:user:7", "tenant:42:user:8", "tenant:4:user:1", "tenant:42", "other", ""} {
+		t.insert(k)
+	}
+	keys := t.keysWithPrefix("tenant:42")
+	sort.Strings(keys)
+	s.Equal([]string{"tenant:42", "tenant:42:user:7", "tenant:42:user:8"}, keys)
+	s.Len(t.keysWithPrefix("tenant:4"), 4)
+	s.Len(t.keysWithPrefix("ten"), 4)
+	s.Len(t.keysWithPrefix(""), 6)
+	s.Empty(t.keysWithPrefix("tenant:5"))
+	s.Empty(t.keysWithPrefix("tenant:42:user:77"))
+
+	t.remove("tenant:42")
+	t.remove("tenant:42:user:7")
+	t.remove("absent")
+	s.Equal([]string{"tenant:42:user:8"}, t.keysWithPrefix("tenant:42"))
+	//nodes are merged after remove
+	s.Equal("enant:4", t.root.children['t'].label[1:])
+}
+
+func (s *suiteDeletePrefix) TestRadixTreeRandom() {
+	rnd := rand.New(rand.NewSource(1))
+	t := newRadixTree()
+	set := map[string]struct{}{}
+	for i := 0; i < 5000; i++ {
+		k := strconv.Itoa(rnd.Intn(500))
+		if rnd.Intn(3) == 0 {
+			t.remove(k)
+			delete(set, k)
+		} else {
+			t.insert(k)
+			set[k] = struct{}{}
+		}
+	}
+	for _, prefix := range []string{"", "1", "12", "123", "9", "99"} {
+		var expected []string
+		for k := range set {
+			if strings.HasPrefix(k, prefix) {
+				expected = append(expected, k)
+			}
+		}
+		s.ElementsMatch(expected, t.keysWithPrefix(prefix), prefix)
+	}
+}
+
+func (s *suiteDeletePrefix) TestAllCaches() {
+	weight := func(v int) uint64 { return 1 }
+	caches := map[string]Cache[string, int]{
+		"lru":        NewLRU[string, int](10, nil),
+		"s2q":        NewSimplified2Q[string, int](7, 3),
+		"2q":         NewFull2Q[string, int](7, 3, 5),
+		"2qa":        NewAdaptiveFull2Q[string, int](10),
+		"mq":         NewMQCache[string, int](4, 10, 10, 10, nil, nil),
+		"lfu":        NewLFU[string, int](10),
+		"lirs":       NewLIRS[string, int](10, 1, 20, nil),
+		"clock":      NewClock[string, int](10, nil),
+		"clockpro":   NewClockPro[string, int](10),
+		"s3fifo":     NewS3FIFO[string, int](10, nil),
+		"sieve":      NewSieve[string, int](10, nil),
+		"gdsf":       NewGDSF[string, int](10, weight, nil),
+		"lruk":       NewLRUK[string, int](2, 10, 10, 0, nil),
+		"slru":       NewSLRU[string, int](3, 7, nil),
+		"random":     NewRandom[string, int](10, 1, nil),
+		"store":      NewStore[string, int](NewLRUPolicy[string](), 10, nil),
+		"indexed":    WithPrefixIndex(NewStore[string, int](NewLRUPolicy[string](), 10, nil)),
+		"sampledlfu": NewSampledLFU[string, int](10, 5, 1, nil),
+		"negative":   NewNegativeCache[string, int](NewLRU[string, int](10, nil), 10),
+	}
+	for name, c := range caches {
+		for i := 0; i < 5; i++ {
+			c.Put("a:"+strconv.Itoa(i), i)
+			c.Put("b:"+strconv.Itoa(i), i)
+		}
+		s.Equal(5, DeletePrefix[int](c.(BulkDeleter[string, int]), "a:"), name)
+		s.Equal(1, c.(BulkDeleter[string, int]).DeleteFunc(func(key string, value int) bool {
+			return 4 == value
+		}), name)
+		//deleted items free their weight
+		for i := 0; i < 6; i++ {
+			c.Put("c:"+strconv.Itoa(i), i)
+		}
+		for i := 0; i < 5; i++ {
+			_, ok := c.Get("a:"+strconv.Itoa(i), 0)
+			s.False(ok, name)
+			_, ok = c.Get("b:"+strconv.Itoa(i), 0)
+			s.Equal(i != 4, ok, name)
+		}
+		for i := 0; i < 6; i++ {
+			_, ok := c.Get("c:"+strconv.Itoa(i), 0)
+			s.True(ok, name)
+		}
+	}
+}
+
+func (s *suiteDeletePrefix) TestNegativeCache() {
+	c := NewNegativeCache[string, int](NewLRU[string, int](10, nil), 10)
+	c.Put("a:1", 1)
+	c.PutAbsent("a:2", 0)
+	c.PutAbsent("b:1", 0)
+	s.Equal(2, DeletePrefix[int](c, "a:"))
+	_, r := c.Lookup("a:1", 0)
+	s.Equal(LookupMiss, r)
+	_, r = c.Lookup("a:2", 0)
+	s.Equal(LookupMiss, r)
+	_, r = c.Lookup("b:1", 0)
+	s.Equal(LookupAbsent, r)
+}
+
+func (s *suiteDeletePrefix) TestIndexedStore() {
+	var removed []string
+	c := WithPrefixIndex(NewStore[string, int](NewLRUPolicy[string](), 3, nil)).
+		WithEvictionCallback(func(key string, _ int, reason RemoveReason) {
+			removed = append(removed, key+":"+reason.String())
+		})
+	c.Put("tenant:1:a", 1)
+	c.Put("tenant:1:b", 2)
+	c.Put("tenant:2:a", 3)
+	c.Put("tenant:2:b", 4)
+	s.Equal([]string{"tenant:1:a:evicted"}, removed)
+	s.Equal(1, DeletePrefix[int](c, "tenant:1:"))
+	s.Equal([]string{"tenant:1:a:evicted", "tenant:1:b:deleted"}, removed)
+	s.Equal(uint64(2), c.Weight())
+	s.Equal([]string{"tenant:2:a", "tenant:2:b"}, sortedKeys(c.cache.index.(*radixTree).keysWithPrefix("")))
+}
+
+func sortedKeys(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,191 @@
+package allcache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	refreshDefaultMinBackoff = time.Second
+	refreshDefaultMaxBackoff = time.Minute
+)
+
+// ErrLoaderPanic - loader panicked, the error wraps it and describes the panic value
+var ErrLoaderPanic = errors.New("loader panicked")
+
+// Loader - loads value of the key from the source of truth
+type Loader[K comparable, T any] func(key K) (T, error)
+
+// Refreshed - entry of RefreshingCache in the underlying cache, SizeCalculator of the cache can use Value
+type Refreshed[T any] struct {
+	Value T
+	// refreshAt, expireAt - soft and hard expiration, zero time means never
+	refreshAt time.Time
+	expireAt  time.Time
+	// retryAt - next refresh after failed one
+	retryAt  time.Time
+	failures uint
+}
+
+// refreshCall - load of the key in progress, waiters of the load wait for done
+type refreshCall[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// RefreshingCache - stale-while-revalidate layer over any Cache:
+// value older than refreshAfter is returned as is, and one asynchronous reload of the key is started,
+// value older than expireAfter is not returned, Get waits for the loader instead.
+// Failed reload keeps the stale value and the next reload is delayed by exponential backoff.
+type RefreshingCache[K comparable, T any] struct {
+	cache  Cache[K, *Refreshed[T]]
+	loader Loader[K, T]
+
+	refreshAfter time.Duration
+	expireAfter  time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+
+	// calls - loads in progress, at most one per key
+	calls map[K]*refreshCall[T]
+	lock  sync.Mutex
+	now   func() time.Time
+}
+
+// NewRefreshingCache - refreshAfter and expireAfter are soft and hard expiration after load, 0 means never.
+// Items of the cache must not be changed by other users of the cache.
+func NewRefreshingCache[K comparable, T any](
+	cache Cache[K, *Refreshed[T]],
+	loader Loader[K, T],
+	refreshAfter, expireAfter time.Duration,
+) *RefreshingCache[K, T] {
+	return &RefreshingCache[K, T]{
+		cache:        cache,
+		loader:       loader,
+		refreshAfter: refreshAfter,
+		expireAfter:  expireAfter,
+		minBackoff:   refreshDefaultMinBackoff,
+		maxBackoff:   refreshDefaultMaxBackoff,
+		calls:        make(map[K]*refreshCall[T]),
+		now:          time.Now,
+	}
+}
+
+// WithBackoff - delay of reload after the first failure is minBackoff, it doubles after every failure up to maxBackoff.
+// Must be called before the cache is used.
+func (c *RefreshingCache[K, T]) WithBackoff(minBackoff, maxBackoff time.Duration) *RefreshingCache[K, T] {
+	c.minBackoff = minBackoff
+	c.maxBackoff = maxBackoff
+	return c
+}
+
+// Get - fresh or stale value of the key, or value loaded by the loader if the key is absent or expired
+func (c *RefreshingCache[K, T]) Get(key K) (T, error) {
+	c.lock.Lock()
+	now := c.now()
+	if e, ok := c.cache.Get(key, nil); ok && !isAfter(now, e.expireAt) {
+		if isAfter(now, e.refreshAt) && !now.Before(e.retryAt) {
+			if _, loading := c.calls[key]; !loading {
+				go c.load(key, c.startCall(key))
+			}
+		}
+		c.lock.Unlock()
+		return e.Value, nil
+	}
+	call, loading := c.calls[key]
+	if !loading {
+		call = c.startCall(key)
+	}
+	c.lock.Unlock()
+	if !loading {
+		c.load(key, call)
+	}
+	<-call.done
+	return call.value, call.err
+}
+
+// Put - put value as if it were loaded now, load of the key in progress is discarded
+func (c *RefreshingCache[K, T]) Put(key K, value T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.calls, key)
+	c.cache.Put(key, c.newEntry(value))
+}
+
+// Delete - delete the key, load of the key in progress is discarded
+func (c *RefreshingCache[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.calls, key)
+	c.cache.Delete(key)
+}
+
+func (c *RefreshingCache[K, T]) startCall(key K) *refreshCall[T] {
+	call := &refreshCall[T]{done: make(chan struct{})}
+	c.calls[key] = call
+	return call
+}
+
+// load - call the loader and store the result, unless the call was discarded by Put or Delete meanwhile
+func (c *RefreshingCache[K, T]) load(key K, call *refreshCall[T]) {
+	call.value, call.err = c.callLoader(key)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	defer close(call.done)
+	if c.calls[key] != call {
+		return
+	}
+	delete(c.calls, key)
+	if nil == call.err {
+		c.cache.Put(key, c.newEntry(call.value))
+		return
+	}
+	if e, ok := c.cache.Get(key, nil); ok {
+		e.failures += 1
+		e.retryAt = c.now().Add(c.backoff(e.failures))
+	}
+}
+
+// callLoader - panic of the loader is returned as ErrLoaderPanic,
+// so waiters of the load are released and asynchronous reload doesn't crash the process
+func (c *RefreshingCache[K, T]) callLoader(key K) (value T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero T
+			value, err = zero, fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+		}
+	}()
+	return c.loader(key)
+}
+
+func (c *RefreshingCache[K, T]) newEntry(value T) *Refreshed[T] {
+	now := c.now()
+	e := &Refreshed[T]{Value: value}
+	if c.refreshAfter > 0 {
+		e.refreshAt = now.Add(c.refreshAfter)
+	}
+	if c.expireAfter > 0 {
+		e.expireAt = now.Add(c.expireAfter)
+	}
+	return e
+}
+
+// backoff - minBackoff * 2^(failures-1), but not more than maxBackoff
+func (c *RefreshingCache[K, T]) backoff(failures uint) time.Duration {
+	d := c.minBackoff
+	for i := uint(1); i < failures && d < c.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	return d
+}
+
+// isAfter - moment is reached, zero moment is never reached
+func isAfter(now, moment time.Time) bool {
+	return !moment.IsZero() && !now.Before(moment)
+}
@@ -0,0 +1,237 @@
+package allcache
+
+import (
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errLoad = errors.New("load failed")
+
+type testClock struct {
+	now  time.Time
+	lock sync.Mutex
+}
+
+func (c *testClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type suiteRefreshingCache struct {
+	suite.Suite
+	cache *RefreshingCache[string, int]
+	clock *testClock
+	// loads - number of loader calls, value of n-th load is n
+	loads int32
+	fail  int32
+	// gate - loader waits for it if it is not nil
+	gate chan struct{}
+}
+
+func TestRefreshingCache(t *testing.T) {
+	suite.Run(t, new(suiteRefreshingCache))
+}
+
+func (s *suiteRefreshingCache) SetupTest() {
+	s.loads, s.fail, s.gate = 0, 0, nil
+	s.clock = &testClock{now: time.Unix(1000, 0)}
+	s.cache = NewRefreshingCache[string, int](
+		NewLRU[string, *Refreshed[int]](10, nil),
+		s.load,
+		time.Minute,
+		time.Hour,
+	).WithBackoff(time.Second, 4*time.Second)
+	s.cache.now = s.clock.Now
+}
+
+func (s *suiteRefreshingCache) load(string) (int, error) {
+	if s.gate != nil {
+		<-s.gate
+	}
+	n := atomic.AddInt32(&s.loads, 1)
+	if atomic.LoadInt32(&s.fail) != 0 {
+		return 0, errLoad
+	}
+	return int(n), nil
+}
+
+// wait - wait for load of the key in progress
+func (s *suiteRefreshingCache) wait(key string) {
+	s.cache.lock.Lock()
+	call := s.cache.calls[key]
+	s.cache.lock.Unlock()
+	if call != nil {
+		<-call.done
+	}
+}
+
+func (s *suiteRefreshingCache) TestLoad() {
+	r, err := s.cache.Get("a")
+	s.NoError(err)
+	s.Equal(1, r)
+	r, err = s.cache.Get("a")
+	s.NoError(err)
+	s.Equal(1, r)
+	s.Equal(int32(1), s.loads)
+
+	atomic.StoreInt32(&s.fail, 1)
+	_, err = s.cache.Get("b")
+	s.ErrorIs(err, errLoad)
+}
+
+func (s *suiteRefreshingCache) TestStaleWhileRevalidate() {
+	s.cache.Get("a")
+	s.clock.Add(2 * time.Minute)
+
+	s.gate = make(chan struct{})
+	r, err := s.cache.Get("a")
+	s.NoError(err)
+	s.Equal(1, r)
+	//only one reload per key
+	r, _ = s.cache.Get("a")
+	s.Equal(1, r)
+	s.Len(s.cache.calls, 1)
+	close(s.gate)
+	s.wait("a")
+
+	r, _ = s.cache.Get("a")
+	s.Equal(2, r)
+	s.Equal(int32(2), s.loads)
+}
+
+func (s *suiteRefreshingCache) TestHardExpiration() {
+	s.cache.Get("a")
+	s.clock.Add(time.Hour)
+	r, err := s.cache.Get("a")
+	s.NoError(err)
+	s.Equal(2, r)
+
+	s.clock.Add(time.Hour)
+	atomic.StoreInt32(&s.fail, 1)
+	_, err = s.cache.Get("a")
+	s.ErrorIs(err, errLoad)
+}
+
+func (s *suiteRefreshingCache) TestBackoff() {
+	s.cache.Get("a")
+	atomic.StoreInt32(&s.fail, 1)
+	s.clock.Add(2 * time.Minute)
+	r, _ := s.cache.Get("a")
+	s.Equal(1, r)
+	s.wait("a")
+	s.Equal(int32(2), s.loads)
+
+	//stale value is kept, reload waits for backoff
+	r, _ = s.cache.Get("a")
+	s.Equal(1, r)
+	s.Empty(s.cache.calls)
+
+	s.clock.Add(time.Second)
+	s.cache.Get("a")
+	s.wait("a")
+	s.Equal(int32(3), s.loads)
+
+	//backoff doubles
+	s.clock.Add(time.Second)
+	s.cache.Get("a")
+	s.Empty(s.cache.calls)
+	s.Equal(4*time.Second, s.cache.backoff(3))
+	s.Equal(4*time.Second, s.cache.backoff(10))
+
+	atomic.StoreInt32(&s.fail, 0)
+	s.clock.Add(time.Second)
+	s.cache.Get("a")
+	s.wait("a")
+	r, _ = s.cache.Get("a")
+	s.Equal(4, r)
+}
+
+func (s *suiteRefreshingCache) TestPutAndDelete() {
+	s.cache.Get("a")
+	s.clock.Add(2 * time.Minute)
+	s.gate = make(chan struct{})
+	s.cache.Get("a")
+	s.cache.lock.Lock()
+	call := s.cache.calls["a"]
+	s.cache.lock.Unlock()
+
+	//reload in progress doesn't override newer value
+	s.cache.Put("a", 10)
+	close(s.gate)
+	<-call.done
+	r, _ := s.cache.Get("a")
+	s.Equal(10, r)
+
+	s.cache.Delete("a")
+	r, _ = s.cache.Get("a")
+	s.Equal(3, r)
+}
+
+func (s *suiteRefreshingCache) TestConcurrentMiss() {
+	s.gate = make(chan struct{})
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = s.cache.Get("a")
+		}(i)
+	}
+	for {
+		s.cache.lock.Lock()
+		n := len(s.cache.calls)
+		s.cache.lock.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(s.gate)
+	wg.Wait()
+	for _, r := range results {
+		s.Equal(1, r)
+	}
+	s.Equal(int32(1), s.loads)
+}
+
+func (s *suiteRefreshingCache) TestLoaderPanic() {
+	s.cache.loader = func(string) (int, error) {
+		atomic.AddInt32(&s.loads, 1)
+		panic("boom")
+	}
+	_, err := s.cache.Get("a")
+	s.ErrorIs(err, ErrLoaderPanic)
+	s.Contains(err.Error(), "boom")
+	s.Empty(s.cache.calls)
+
+	//the key is loaded again
+	s.cache.loader = s.load
+	r, err := s.cache.Get("a")
+	s.NoError(err)
+	s.Equal(2, r)
+
+	//panic of asynchronous reload keeps the stale value
+	s.cache.loader = func(string) (int, error) {
+		panic("boom")
+	}
+	s.clock.Add(2 * time.Minute)
+	r, _ = s.cache.Get("a")
+	s.Equal(2, r)
+	s.wait("a")
+	s.Empty(s.cache.calls)
+	r, _ = s.cache.Get("a")
+	s.Equal(2, r)
+	s.Empty(s.cache.calls)
+}
@@ -0,0 +1,250 @@
+package allcache
+
+import (
+	"github.com/satmaelstorm/list"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	s3FIFOMaxFreq = 3
+	// s3FIFOSmallPercent - size of small queue in percents of cache size
+	s3FIFOSmallPercent = 10
+)
+
+// S3FIFO - small FIFO for new entries, main FIFO with reinsertion of frequently used entries
+// and ghost FIFO of keys evicted from small queue @see https://dl.acm.org/doi/10.1145/3600006.3613147
+// Hit only increases frequency of the entry, so Get needs read lock only.
+type S3FIFO[K comparable, T any] struct {
+	cache *ntsS3FIFO[K, T]
+	lock  sync.RWMutex
+}
+
+// NewS3FIFO - small queue takes 10% of maxSize, ghost queue remembers keys with total size up to main queue size.
+// Items larger than maxSize are not cached.
+func NewS3FIFO[K comparable, T any](maxSize uint64, calcSize SizeCalculator[T]) Cache[K, T] {
+	cache := new(S3FIFO[K, T])
+	cache.cache = newNtsS3FIFO[K, T](maxSize, calcSize)
+	return cache
+}
+
+func (c *S3FIFO[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *S3FIFO[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.cache.get(key, def)
+}
+
+func (c *S3FIFO[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *S3FIFO[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe S3-FIFO, except get, which is safe with other get.
+// New entries are enqueued to the tail, entries are evicted from the head.
+type ntsS3FIFO[K comparable, T any] struct {
+	items map[K]*list.Node[*cacheEntryS3FIFO[K, T]]
+	small *list.Queue[*cacheEntryS3FIFO[K, T]]
+	main  *list.Queue[*cacheEntryS3FIFO[K, T]]
+
+	itemsGhost map[K]*list.Node[cacheEntryGhost[K]]
+	ghost      *list.Queue[cacheEntryGhost[K]]
+
+	maxSize   uint64
+	smallSize uint64
+	mainSize  uint64
+
+	smallWeight uint64
+	mainWeight  uint64
+	ghostWeight uint64
+
+	sizeCalc SizeCalculator[T]
+}
+
+func newNtsS3FIFO[K comparable, T any](maxSize uint64, sizeCalc SizeCalculator[T]) *ntsS3FIFO[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	smallSize := maxSize * s3FIFOSmallPercent / 100
+	if smallSize < 1 {
+		smallSize = 1
+	}
+	if smallSize > maxSize {
+		smallSize = maxSize
+	}
+	return &ntsS3FIFO[K, T]{
+		items: make(map[K]*list.Node[*cacheEntryS3FIFO[K, T]], maxSize),
+		small: list.NewQueue[*cacheEntryS3FIFO[K, T]](),
+		main:  list.NewQueue[*cacheEntryS3FIFO[K, T]](),
+
+		itemsGhost: make(map[K]*list.Node[cacheEntryGhost[K]]),
+		ghost:      list.NewQueue[cacheEntryGhost[K]](),
+
+		maxSize:   maxSize,
+		smallSize: smallSize,
+		mainSize:  maxSize - smallSize,
+
+		sizeCalc: sizeCalc,
+	}
+}
+
+func (c *ntsS3FIFO[K, T]) get(key K, def T) (T, bool) {
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		c.hit(entry)
+		return entry.value, true
+	}
+	return def, false
+}
+
+// hit - increase frequency up to s3FIFOMaxFreq
+func (c *ntsS3FIFO[K, T]) hit(entry *cacheEntryS3FIFO[K, T]) {
+	for {
+		freq := atomic.LoadUint32(&entry.freq)
+		if freq >= s3FIFOMaxFreq || atomic.CompareAndSwapUint32(&entry.freq, freq, freq+1) {
+			return
+		}
+	}
+}
+
+func (c *ntsS3FIFO[K, T]) put(key K, value T) {
+	size := c.sizeCalc(value)
+	if size > c.maxSize {
+		c.delete(key)
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		if entry.isMain {
+			c.mainWeight += size - entry.size
+		} else {
+			c.smallWeight += size - entry.size
+		}
+		entry.value = value
+		entry.size = size
+		c.hit(entry)
+		c.reclaim(0)
+		return
+	}
+
+	c.reclaim(size)
+
+	entry := &cacheEntryS3FIFO[K, T]{size: size}
+	entry.key = key
+	entry.value = value
+	if g, ok := c.itemsGhost[key]; ok {
+		c.removeGhost(g)
+		c.toMain(entry)
+	} else {
+		c.small.Enqueue(entry)
+		c.smallWeight += size
+		c.items[key] = c.small.Tail()
+	}
+}
+
+func (c *ntsS3FIFO[K, T]) delete(key K) {
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		if entry.isMain {
+			c.main.Remove(e)
+			c.mainWeight -= entry.size
+		} else {
+			c.small.Remove(e)
+			c.smallWeight -= entry.size
+		}
+		delete(c.items, key)
+	}
+	if g, ok := c.itemsGhost[key]; ok {
+		c.removeGhost(g)
+	}
+}
+
+func (c *ntsS3FIFO[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.Value().value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+func (c *ntsS3FIFO[K, T]) reclaim(size uint64) {
+	for c.smallWeight+c.mainWeight+size > c.maxSize {
+		if c.small.Len() > 0 && (c.smallWeight >= c.smallSize || 0 == c.main.Len()) {
+			c.evictSmall()
+		} else if c.main.Len() > 0 {
+			c.evictMain()
+		} else {
+			return
+		}
+	}
+}
+
+// evictSmall - entries used while in small queue go to main queue, the first unused one goes to ghost queue
+func (c *ntsS3FIFO[K, T]) evictSmall() {
+	for e := c.small.Dequeue(); e != nil; e = c.small.Dequeue() {
+		entry := e.Value()
+		c.smallWeight -= entry.size
+		if atomic.LoadUint32(&entry.freq) > 0 {
+			atomic.StoreUint32(&entry.freq, 0)
+			c.toMain(entry)
+			for c.mainWeight > c.mainSize && c.main.Len() > 1 {
+				c.evictMain()
+			}
+			continue
+		}
+		delete(c.items, entry.key)
+		c.ghost.Enqueue(cacheEntryGhost[K]{key: entry.key, size: entry.size})
+		c.itemsGhost[entry.key] = c.ghost.Tail()
+		c.ghostWeight += entry.size
+		for c.ghostWeight > c.mainSize {
+			c.removeGhost(c.ghost.Head())
+		}
+		return
+	}
+}
+
+// evictMain - entries with non zero frequency are reinserted with decreased frequency, the first other is evicted
+func (c *ntsS3FIFO[K, T]) evictMain() {
+	for e := c.main.Head(); e != nil; e = c.main.Head() {
+		entry := e.Value()
+		if freq := atomic.LoadUint32(&entry.freq); freq > 0 {
+			atomic.StoreUint32(&entry.freq, freq-1)
+			c.main.MoveToBack(e)
+			continue
+		}
+		c.main.Dequeue()
+		c.mainWeight -= entry.size
+		delete(c.items, entry.key)
+		return
+	}
+}
+
+func (c *ntsS3FIFO[K, T]) toMain(entry *cacheEntryS3FIFO[K, T]) {
+	entry.isMain = true
+	c.main.Enqueue(entry)
+	c.mainWeight += entry.size
+	c.items[entry.key] = c.main.Tail()
+}
+
+func (c *ntsS3FIFO[K, T]) removeGhost(g *list.Node[cacheEntryGhost[K]]) {
+	c.ghost.Remove(g)
+	delete(c.itemsGhost, g.Value().key)
+	c.ghostWeight -= g.Value().size
+}
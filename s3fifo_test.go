@@ -0,0 +1,145 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsS3FIFO struct {
+	suite.Suite
+	cache *ntsS3FIFO[string, int]
+}
+
+func TestNtsS3FIFO(t *testing.T) {
+	suite.Run(t, new(suiteNtsS3FIFO))
+}
+
+func (s *suiteNtsS3FIFO) SetupTest() {
+	//small queue is 1, main queue is 9
+	s.cache = newNtsS3FIFO[string, int](10, nil)
+	for i := 1; i <= 10; i++ {
+		s.cache.put(strconv.Itoa(i), i)
+	}
+}
+
+func (s *suiteNtsS3FIFO) TestFill() {
+	s.Equal(uint64(1), s.cache.smallSize)
+	s.Equal(uint64(9), s.cache.mainSize)
+	for i := 1; i <= 10; i++ {
+		r, ok := s.cache.get(strconv.Itoa(i), 0)
+		s.True(ok)
+		s.Equal(i, r)
+	}
+	s.Equal(10, s.cache.small.Len())
+	s.Equal(0, s.cache.main.Len())
+}
+
+func (s *suiteNtsS3FIFO) TestQuickDemotion() {
+	s.cache.get("1", 0)
+	s.cache.put("11", 11)
+	//1 was used and goes to main, 2 was not used and goes to ghost
+	s.True(s.cache.items["1"].Value().isMain)
+	s.Equal(uint32(0), s.cache.items["1"].Value().freq)
+	_, ok := s.cache.get("2", 0)
+	s.False(ok)
+	s.Contains(s.cache.itemsGhost, "2")
+
+	//ghost hit goes directly to main
+	s.cache.put("2", 2)
+	s.NotContains(s.cache.itemsGhost, "2")
+	s.True(s.cache.items["2"].Value().isMain)
+	s.Equal(uint64(10), s.cache.smallWeight+s.cache.mainWeight)
+}
+
+func (s *suiteNtsS3FIFO) TestFrequency() {
+	for i := 0; i < 10; i++ {
+		s.cache.get("5", 0)
+	}
+	s.Equal(uint32(s3FIFOMaxFreq), s.cache.items["5"].Value().freq)
+}
+
+func (s *suiteNtsS3FIFO) TestMainReinsertion() {
+	c := newNtsS3FIFO[int, int](10, nil)
+	for i := 0; i < 10; i++ {
+		c.put(i, i)
+		c.get(i, 0)
+	}
+	for i := 10; i < 20; i++ {
+		c.put(i, i)
+	}
+	s.Equal(9, c.main.Len())
+	var hot []int
+	for e := c.main.Head(); e != nil; e = e.Next() {
+		hot = append(hot, e.Value().key)
+	}
+	//entries of main queue survive scan of new keys
+	for i := 100; i < 130; i++ {
+		c.put(i, i)
+	}
+	for _, k := range hot {
+		_, ok := c.get(k, 0)
+		s.True(ok, k)
+	}
+	s.Equal(uint64(10), c.smallWeight+c.mainWeight)
+
+	//all hot entries were used, 200 moves to main with zero frequency and is evicted first
+	c.put(200, 200)
+	c.get(200, 0)
+	c.put(201, 201)
+	_, ok := c.get(200, 0)
+	s.False(ok)
+	for _, k := range hot {
+		_, ok := c.get(k, 0)
+		s.True(ok, k)
+	}
+	s.LessOrEqual(c.ghostWeight, c.mainSize)
+}
+
+func (s *suiteNtsS3FIFO) TestDelete() {
+	s.cache.get("2", 0)
+	s.cache.put("11", 11)
+	s.cache.delete("2")
+	s.cache.delete("1")
+	s.cache.delete("11")
+	s.NotContains(s.cache.items, "2")
+	s.NotContains(s.cache.itemsGhost, "1")
+	s.Equal(uint64(0), s.cache.mainWeight)
+	s.Equal(uint64(0), s.cache.ghostWeight)
+	s.Equal(uint64(8), s.cache.smallWeight)
+}
+
+func (s *suiteNtsS3FIFO) TestSizeCalculator() {
+	c := newNtsS3FIFO[string, int](100, func(v int) uint64 { return uint64(v) })
+	c.put("big", 101)
+	_, ok := c.get("big", 0)
+	s.False(ok)
+	for i := 0; i < 100; i++ {
+		k := strconv.Itoa(i % 13)
+		if _, ok := c.get(k, 0); !ok {
+			c.put(k, i%20+1)
+		}
+		s.LessOrEqual(c.smallWeight+c.mainWeight, uint64(100))
+	}
+	c.put("a", 50)
+	c.put("a", 70)
+	s.LessOrEqual(c.smallWeight+c.mainWeight, uint64(100))
+	r, ok := c.get("a", 0)
+	s.True(ok)
+	s.Equal(70, r)
+}
+
+func (s *suiteNtsS3FIFO) TestTSVersion() {
+	c := NewS3FIFO[int, int](3, nil)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
@@ -0,0 +1,195 @@
+package allcache
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// SampledCache - evicts the worst of randomly sampled entries, like Redis does.
+// Entries are stored in dense slice, so there are no lists to maintain and sampling is cheap.
+type SampledCache[K comparable, T any] struct {
+	cache *ntsSampled[K, T]
+	lock  sync.Mutex
+}
+
+// NewRandom - evicts uniformly random entry, seed makes evictions deterministic.
+// Items larger than maxSize are not cached.
+func NewRandom[K comparable, T any](maxSize uint64, seed int64, calcSize SizeCalculator[T]) Cache[K, T] {
+	cache := new(SampledCache[K, T])
+	cache.cache = newNtsSampled[K, T](maxSize, 1, seed, nil, calcSize)
+	return cache
+}
+
+// NewSampledLFU - evicts the least frequently used of samples random entries, Redis uses 5 samples.
+// Items larger than maxSize are not cached.
+func NewSampledLFU[K comparable, T any](maxSize uint64, samples int, seed int64, calcSize SizeCalculator[T]) Cache[K, T] {
+	cache := new(SampledCache[K, T])
+	cache.cache = newNtsSampled[K, T](maxSize, samples, seed, lessFrequent[K, T], calcSize)
+	return cache
+}
+
+// NewSampledLRU - evicts the least recently used of samples random entries, Redis uses 5 samples.
+// Items larger than maxSize are not cached.
+func NewSampledLRU[K comparable, T any](maxSize uint64, samples int, seed int64, calcSize SizeCalculator[T]) Cache[K, T] {
+	cache := new(SampledCache[K, T])
+	cache.cache = newNtsSampled[K, T](maxSize, samples, seed, lessRecent[K, T], calcSize)
+	return cache
+}
+
+func (c *SampledCache[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *SampledCache[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.get(key, def)
+}
+
+func (c *SampledCache[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *SampledCache[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// sampledVictimLess - true if a is a better victim than b
+type sampledVictimLess[K comparable, T any] func(a, b *cacheEntrySampled[K, T]) bool
+
+func lessFrequent[K comparable, T any](a, b *cacheEntrySampled[K, T]) bool {
+	return a.freq < b.freq
+}
+
+func lessRecent[K comparable, T any](a, b *cacheEntrySampled[K, T]) bool {
+	return a.last < b.last
+}
+
+// non thread safe sampled eviction
+type ntsSampled[K comparable, T any] struct {
+	items   map[K]*cacheEntrySampled[K, T]
+	entries []*cacheEntrySampled[K, T]
+
+	samples int
+	less    sampledVictimLess[K, T]
+	rnd     *rand.Rand
+	time    uint64
+
+	length   uint64
+	maxSize  uint64
+	sizeCalc SizeCalculator[T]
+}
+
+func newNtsSampled[K comparable, T any](
+	maxSize uint64,
+	samples int,
+	seed int64,
+	less sampledVictimLess[K, T],
+	sizeCalc SizeCalculator[T],
+) *ntsSampled[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	if samples < 1 {
+		samples = 1
+	}
+	return &ntsSampled[K, T]{
+		items:    make(map[K]*cacheEntrySampled[K, T]),
+		samples:  samples,
+		less:     less,
+		rnd:      rand.New(rand.NewSource(seed)),
+		maxSize:  maxSize,
+		sizeCalc: sizeCalc,
+	}
+}
+
+func (c *ntsSampled[K, T]) get(key K, def T) (T, bool) {
+	c.time += 1
+	if entry, ok := c.items[key]; ok {
+		entry.freq += 1
+		entry.last = c.time
+		return entry.value, true
+	}
+	return def, false
+}
+
+func (c *ntsSampled[K, T]) put(key K, value T) {
+	c.time += 1
+	size := c.sizeCalc(value)
+	if size > c.maxSize {
+		c.delete(key)
+		return
+	}
+	if entry, ok := c.items[key]; ok {
+		c.length += size - entry.size
+		entry.value = value
+		entry.size = size
+		entry.freq += 1
+		entry.last = c.time
+		c.reclaim(0)
+		return
+	}
+	c.reclaim(size)
+	entry := &cacheEntrySampled[K, T]{size: size, freq: 1, last: c.time, pos: len(c.entries)}
+	entry.key = key
+	entry.value = value
+	c.entries = append(c.entries, entry)
+	c.items[key] = entry
+	c.length += size
+}
+
+func (c *ntsSampled[K, T]) delete(key K) {
+	if entry, ok := c.items[key]; ok {
+		c.remove(entry)
+	}
+}
+
+func (c *ntsSampled[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+func (c *ntsSampled[K, T]) reclaim(size uint64) {
+	for c.length+size > c.maxSize && len(c.entries) > 0 {
+		c.remove(c.victim())
+	}
+}
+
+// victim - the worst of samples entries chosen with replacement
+func (c *ntsSampled[K, T]) victim() *cacheEntrySampled[K, T] {
+	victim := c.entries[c.rnd.Intn(len(c.entries))]
+	if nil == c.less {
+		return victim
+	}
+	for i := 1; i < c.samples; i++ {
+		if e := c.entries[c.rnd.Intn(len(c.entries))]; c.less(e, victim) {
+			victim = e
+		}
+	}
+	return victim
+}
+
+// remove - the last entry takes place of removed one
+func (c *ntsSampled[K, T]) remove(entry *cacheEntrySampled[K, T]) {
+	last := len(c.entries) - 1
+	c.entries[entry.pos] = c.entries[last]
+	c.entries[entry.pos].pos = entry.pos
+	c.entries[last] = nil
+	c.entries = c.entries[:last]
+	delete(c.items, entry.key)
+	c.length -= entry.size
+}
@@ -0,0 +1,122 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsSampled struct {
+	suite.Suite
+}
+
+func TestNtsSampled(t *testing.T) {
+	suite.Run(t, new(suiteNtsSampled))
+}
+
+func (s *suiteNtsSampled) checkDense(c *ntsSampled[string, int]) {
+	s.Equal(len(c.items), len(c.entries))
+	for i, e := range c.entries {
+		s.Equal(i, e.pos)
+		s.Same(e, c.items[e.key])
+	}
+}
+
+func (s *suiteNtsSampled) keys(c *ntsSampled[string, int]) []string {
+	keys := make([]string, 0, len(c.entries))
+	for _, e := range c.entries {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func (s *suiteNtsSampled) TestRandomIsDeterministic() {
+	fill := func() *ntsSampled[string, int] {
+		c := newNtsSampled[string, int](10, 1, 42, nil, nil)
+		for i := 0; i < 100; i++ {
+			c.put(strconv.Itoa(i), i)
+		}
+		return c
+	}
+	c1, c2 := fill(), fill()
+	s.Equal(s.keys(c1), s.keys(c2))
+	s.Len(c1.entries, 10)
+	s.Equal(uint64(10), c1.length)
+	s.checkDense(c1)
+}
+
+func (s *suiteNtsSampled) TestSampledLFU() {
+	//with many samples the least frequently used entry is found
+	c := newNtsSampled[string, int](3, 64, 1, lessFrequent[string, int], nil)
+	for i := 1; i <= 3; i++ {
+		c.put(strconv.Itoa(i), i)
+	}
+	c.get("1", 0)
+	c.get("1", 0)
+	c.get("3", 0)
+	c.put("4", 4)
+	_, ok := c.get("2", 0)
+	s.False(ok)
+	c.get("4", 0)
+	c.put("5", 5)
+	_, ok = c.get("3", 0)
+	s.False(ok)
+	s.checkDense(c)
+}
+
+func (s *suiteNtsSampled) TestSampledLRU() {
+	c := newNtsSampled[string, int](3, 64, 1, lessRecent[string, int], nil)
+	for i := 1; i <= 3; i++ {
+		c.put(strconv.Itoa(i), i)
+	}
+	c.get("1", 0)
+	c.put("4", 4)
+	_, ok := c.get("2", 0)
+	s.False(ok)
+	c.put("5", 5)
+	_, ok = c.get("3", 0)
+	s.False(ok)
+	s.checkDense(c)
+}
+
+func (s *suiteNtsSampled) TestPutAndDelete() {
+	c := newNtsSampled[string, int](10, 5, 1, lessRecent[string, int], func(v int) uint64 { return uint64(v) })
+	c.put("a", 4)
+	c.put("b", 4)
+	c.put("big", 11)
+	_, ok := c.get("big", 0)
+	s.False(ok)
+
+	c.put("a", 6)
+	s.Equal(uint64(10), c.length)
+	r, ok := c.get("a", 0)
+	s.True(ok)
+	s.Equal(6, r)
+
+	c.delete("a")
+	_, ok = c.get("a", 0)
+	s.False(ok)
+	s.Equal(uint64(4), c.length)
+	s.Equal([]string{"b"}, s.keys(c))
+	s.checkDense(c)
+}
+
+func (s *suiteNtsSampled) TestTSVersion() {
+	for _, c := range []Cache[int, int]{
+		NewRandom[int, int](3, 1, nil),
+		NewSampledLFU[int, int](3, 5, 1, nil),
+		NewSampledLRU[int, int](3, 5, 1, nil),
+	} {
+		c.Put(1, 1)
+
+		r, ok := c.Get(1, 0)
+		s.True(ok)
+		s.Equal(1, r)
+
+		c.Delete(1)
+
+		r, ok = c.Get(1, 0)
+		s.False(ok)
+		s.Equal(0, r)
+	}
+}
@@ -0,0 +1,152 @@
+package allcache
+
+import (
+	"github.com/satmaelstorm/list"
+	"sync"
+	"sync/atomic"
+)
+
+// Sieve - SIEVE eviction policy @see https://www.usenix.org/conference/nsdi24/presentation/zhang-yazhuo
+// Hit only sets visited bit of the entry and never moves it, so Get needs read lock only.
+type Sieve[K comparable, T any] struct {
+	cache *ntsSieve[K, T]
+	lock  sync.RWMutex
+}
+
+// NewSieve - items larger than maxSize are not cached
+func NewSieve[K comparable, T any](maxSize uint64, calcSize SizeCalculator[T]) Cache[K, T] {
+	cache := new(Sieve[K, T])
+	cache.cache = newNtsSieve[K, T](maxSize, calcSize)
+	return cache
+}
+
+func (c *Sieve[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *Sieve[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.cache.get(key, def)
+}
+
+func (c *Sieve[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *Sieve[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe SIEVE, except get, which is safe with other get.
+// New entries are enqueued to the tail of the queue, so the oldest entry is at the head.
+// The hand moves from the oldest entry to the newest one and wraps around to the head.
+type ntsSieve[K comparable, T any] struct {
+	items map[K]*list.Node[*cacheEntryClock[K, T]]
+	queue *list.Queue[*cacheEntryClock[K, T]]
+	// hand - next candidate for eviction, nil means the head of the queue
+	hand     *list.Node[*cacheEntryClock[K, T]]
+	length   uint64
+	maxSize  uint64
+	sizeCalc SizeCalculator[T]
+}
+
+func newNtsSieve[K comparable, T any](maxSize uint64, sizeCalc SizeCalculator[T]) *ntsSieve[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	return &ntsSieve[K, T]{
+		items:    make(map[K]*list.Node[*cacheEntryClock[K, T]], maxSize),
+		queue:    list.NewQueue[*cacheEntryClock[K, T]](),
+		maxSize:  maxSize,
+		sizeCalc: sizeCalc,
+	}
+}
+
+func (c *ntsSieve[K, T]) get(key K, def T) (T, bool) {
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		atomic.StoreUint32(&entry.ref, 1)
+		return entry.value, true
+	}
+	return def, false
+}
+
+func (c *ntsSieve[K, T]) put(key K, value T) {
+	size := c.sizeCalc(value)
+	if size > c.maxSize {
+		c.delete(key)
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		c.length += size - entry.size
+		entry.value = value
+		entry.size = size
+		atomic.StoreUint32(&entry.ref, 1)
+		c.reclaim(0)
+		return
+	}
+	c.reclaim(size)
+	entry := &cacheEntryClock[K, T]{size: size}
+	entry.key = key
+	entry.value = value
+	c.queue.Enqueue(entry)
+	c.items[key] = c.queue.Tail()
+	c.length += size
+}
+
+func (c *ntsSieve[K, T]) delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+func (c *ntsSieve[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.Value().value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+// reclaim - move the hand: visited entries lose the bit and stay in place, the first unvisited one is evicted
+func (c *ntsSieve[K, T]) reclaim(size uint64) {
+	for c.length+size > c.maxSize {
+		if nil == c.hand {
+			c.hand = c.queue.Head()
+		}
+		h := c.hand
+		if nil == h {
+			return
+		}
+		entry := h.Value()
+		if atomic.LoadUint32(&entry.ref) != 0 {
+			atomic.StoreUint32(&entry.ref, 0)
+			c.hand = h.Next()
+			continue
+		}
+		c.remove(h)
+	}
+}
+
+// remove - remove entry, the hand moves to the next newer entry
+func (c *ntsSieve[K, T]) remove(e *list.Node[*cacheEntryClock[K, T]]) {
+	_, next := c.queue.Remove(e)
+	if c.hand == e {
+		c.hand = next
+	}
+	delete(c.items, e.Value().key)
+	c.length -= e.Value().size
+}
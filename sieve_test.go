@@ -0,0 +1,127 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsSieve struct {
+	suite.Suite
+	cache *ntsSieve[string, int]
+}
+
+func TestNtsSieve(t *testing.T) {
+	suite.Run(t, new(suiteNtsSieve))
+}
+
+func (s *suiteNtsSieve) SetupTest() {
+	s.cache = newNtsSieve[string, int](3, nil)
+	s.cache.put("1", 1)
+	s.cache.put("2", 2)
+	s.cache.put("3", 3)
+}
+
+func (s *suiteNtsSieve) TestLazyPromotion() {
+	s.cache.get("1", 0)
+	s.cache.get("2", 0)
+	s.cache.put("4", 4)
+
+	//visited entries keep their places and lose the bit, the hand passed the newest entry and wraps around
+	_, ok := s.cache.get("3", 0)
+	s.False(ok)
+	s.Equal("1", s.cache.queue.Head().Value().key)
+	s.Equal(uint32(0), s.cache.items["1"].Value().ref)
+	s.Nil(s.cache.hand)
+
+	s.cache.put("5", 5)
+	_, ok = s.cache.get("1", 0)
+	s.False(ok)
+	s.Equal("2", s.cache.hand.Value().key)
+	s.Equal(uint64(3), s.cache.length)
+}
+
+func (s *suiteNtsSieve) TestPutAndDelete() {
+	s.cache.put("3", 30)
+	r, ok := s.cache.get("3", 0)
+	s.True(ok)
+	s.Equal(30, r)
+
+	s.cache.get("1", 0)
+	s.cache.put("4", 4)
+	_, ok = s.cache.get("2", 0)
+	s.False(ok)
+	s.Equal("3", s.cache.hand.Value().key)
+
+	//the hand moves to the next newer entry
+	s.cache.delete("3")
+	s.Equal("4", s.cache.hand.Value().key)
+	s.Equal(2, s.cache.queue.Len())
+	s.Equal(uint64(2), s.cache.length)
+}
+
+func (s *suiteNtsSieve) TestSizeCalculator() {
+	c := newNtsSieve[string, int](10, func(v int) uint64 { return uint64(v) })
+	c.put("a", 4)
+	c.put("b", 4)
+	c.put("big", 11)
+	_, ok := c.get("big", 0)
+	s.False(ok)
+
+	c.get("a", 0)
+	c.put("c", 5)
+	_, ok = c.get("b", 0)
+	s.False(ok)
+	_, ok = c.get("a", 0)
+	s.True(ok)
+	s.Equal(uint64(9), c.length)
+
+	c.put("a", 6)
+	s.LessOrEqual(c.length, uint64(10))
+	r, ok := c.get("a", 0)
+	s.True(ok)
+	s.Equal(6, r)
+}
+
+func (s *suiteNtsSieve) TestTSVersion() {
+	c := NewSieve[int, int](3, nil)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
+
+// TestHitRatio - popular keys mixed with one-hit wonders, SIEVE filters the latter out quickly
+func (s *suiteNtsSieve) TestHitRatio() {
+	hitRatio := func(c Cache[string, int]) float64 {
+		rnd := rand.New(rand.NewSource(1))
+		hits, unique := 0, 0
+		const requests = 100000
+		for i := 0; i < requests; i++ {
+			var k string
+			if rnd.Intn(2) == 0 {
+				k = strconv.Itoa(rnd.Intn(80))
+			} else {
+				unique += 1
+				k = "u" + strconv.Itoa(unique)
+			}
+			if _, ok := c.Get(k, 0); ok {
+				hits += 1
+			} else {
+				c.Put(k, i)
+			}
+		}
+		return float64(hits) / requests
+	}
+	lru := hitRatio(NewLRU[string, int](100, nil))
+	sieve := hitRatio(NewSieve[string, int](100, nil))
+	s.Greater(sieve, lru)
+}
@@ -0,0 +1,61 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/satmaelstorm/allcache/trace"
+	"github.com/satmaelstorm/allcache/workload"
+)
+
+const (
+	benchCapacity = 1000
+	benchKeys     = 10 * benchCapacity
+	benchRequests = 1 << 17
+)
+
+type benchWorkload struct {
+	name string
+	gen  func() workload.Generator
+}
+
+var benchWorkloads = []benchWorkload{
+	{"zipf-0.8", func() workload.Generator { return workload.NewZipf(1, benchKeys, 0.8) }},
+	{"zipf-1.0", func() workload.Generator { return workload.NewZipf(1, benchKeys, 1.0) }},
+	{"uniform", func() workload.Generator { return workload.NewUniform(1, benchKeys) }},
+	{"scan-mix", func() workload.Generator {
+		return workload.NewScanMix(1, benchCapacity/2, 2*benchCapacity, 4*benchCapacity)
+	}},
+	{"loop", func() workload.Generator { return workload.NewLoop(benchCapacity + benchCapacity/5) }},
+	{"hotspot", func() workload.Generator {
+		return workload.NewHotspot(1, benchKeys, benchCapacity/2, 0.9, 10*benchCapacity)
+	}},
+}
+
+// BenchmarkPolicies - every policy against every workload, hit-ratio metric is reported with time per request
+func BenchmarkPolicies(b *testing.B) {
+	for _, w := range benchWorkloads {
+		requests, err := trace.ReadAll(workload.Reader(w.gen(), benchRequests))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, p := range Policies() {
+			b.Run(w.name+"/"+p.Name, func(b *testing.B) {
+				cache, err := p.New(benchCapacity, false)
+				if err != nil {
+					b.Fatal(err)
+				}
+				hits := 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					r := requests[i%len(requests)]
+					if _, ok := cache.Get(r.Key, 0); ok {
+						hits += 1
+						continue
+					}
+					cache.Put(r.Key, r.Size)
+				}
+				b.ReportMetric(float64(hits)/float64(b.N), "hit-ratio")
+			})
+		}
+	}
+}
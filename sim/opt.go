@@ -0,0 +1,198 @@
+package sim
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+const (
+	// OptName - name of Belady OPT in results
+	OptName = "opt"
+	// optSamples - number of sampled objects of size aware OPT
+	optSamples = 64
+	never      = math.MaxInt
+)
+
+type optEntry struct {
+	key  string
+	size uint64
+	next int
+	// pos - index in optHeap or optSample
+	pos int
+}
+
+// optResident - resident objects of OPT
+type optResident interface {
+	add(e *optEntry)
+	update(e *optEntry, next int)
+	victim(now int) *optEntry
+	remove(e *optEntry)
+}
+
+// OPT - replay trace with Belady's optimal offline policy, it is an upper bound of hit ratio for other policies.
+// The object with the furthest next use is evicted and the requested object is not cached if its next use is
+// further than next use of any cached object.
+// If weighted is true capacity is in bytes and size aware variant is used: the object with the largest
+// product of next use distance and size of 64 sampled objects is evicted, as Belady-Size does
+// @see https://www.usenix.org/conference/nsdi20/presentation/song
+// Objects which are never used again are not cached at all.
+func OPT(requests []trace.Request, capacity uint64, weighted bool) Result {
+	res := Result{Policy: OptName, Capacity: capacity}
+	next := nextUse(requests)
+	resident := make(map[string]*optEntry)
+	var o optResident = new(optHeap)
+	if weighted {
+		o = &optSample{rnd: rand.New(rand.NewSource(1))}
+	}
+
+	length := uint64(0)
+	start := time.Now()
+	for i, a := range requests {
+		e, ok := resident[a.Key]
+		if trace.OpDelete == a.Op {
+			if ok {
+				o.remove(e)
+				delete(resident, a.Key)
+				length -= e.size
+			}
+			continue
+		}
+		res.Requests += 1
+		res.Bytes += a.Size
+		if ok {
+			res.Hits += 1
+			res.HitBytes += a.Size
+			o.update(e, next[i])
+			continue
+		}
+		size := uint64(1)
+		if weighted {
+			size = a.Size
+		}
+		if size > capacity || never == next[i] {
+			continue
+		}
+		for length+size > capacity {
+			victim := o.victim(i)
+			if !weighted && victim.next <= next[i] {
+				break
+			}
+			o.remove(victim)
+			delete(resident, victim.key)
+			length -= victim.size
+			res.Evictions += 1
+		}
+		if length+size > capacity {
+			continue
+		}
+		e = &optEntry{key: a.Key, size: size, next: next[i]}
+		o.add(e)
+		resident[a.Key] = e
+		length += size
+	}
+	res.Duration = time.Since(start)
+	return res
+}
+
+// nextUse - index of the next request of the same key for every request,
+// never if key isn't requested again or it is deleted before
+func nextUse(requests []trace.Request) []int {
+	result := make([]int, len(requests))
+	last := make(map[string]int)
+	for i := len(requests) - 1; i >= 0; i-- {
+		a := requests[i]
+		if trace.OpDelete == a.Op {
+			last[a.Key] = never
+			continue
+		}
+		if n, ok := last[a.Key]; ok {
+			result[i] = n
+		} else {
+			result[i] = never
+		}
+		last[a.Key] = i
+	}
+	return result
+}
+
+// optHeap - heap with the furthest next use on top
+type optHeap []*optEntry
+
+func (h optHeap) Len() int           { return len(h) }
+func (h optHeap) Less(i, j int) bool { return h[i].next > h[j].next }
+
+func (h optHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+
+func (h *optHeap) Push(x any) {
+	e := x.(*optEntry)
+	e.pos = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *optHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+func (h *optHeap) add(e *optEntry) {
+	heap.Push(h, e)
+}
+
+func (h *optHeap) update(e *optEntry, next int) {
+	e.next = next
+	heap.Fix(h, e.pos)
+}
+
+func (h *optHeap) victim(int) *optEntry {
+	return (*h)[0]
+}
+
+func (h *optHeap) remove(e *optEntry) {
+	heap.Remove(h, e.pos)
+}
+
+// optSample - dense slice of objects for sampling, victim has the largest product of next use distance and size
+type optSample struct {
+	entries []*optEntry
+	rnd     *rand.Rand
+}
+
+func (o *optSample) add(e *optEntry) {
+	e.pos = len(o.entries)
+	o.entries = append(o.entries, e)
+}
+
+func (o *optSample) update(e *optEntry, next int) {
+	e.next = next
+}
+
+func (o *optSample) victim(now int) *optEntry {
+	var victim *optEntry
+	score := -1.0
+	for i := 0; i < optSamples; i++ {
+		e := o.entries[o.rnd.Intn(len(o.entries))]
+		if s := float64(e.next-now) * float64(e.size); s > score {
+			victim, score = e, s
+		}
+	}
+	return victim
+}
+
+func (o *optSample) remove(e *optEntry) {
+	last := len(o.entries) - 1
+	o.entries[e.pos] = o.entries[last]
+	o.entries[e.pos].pos = e.pos
+	o.entries[last] = nil
+	o.entries = o.entries[:last]
+}
@@ -0,0 +1,201 @@
+package sim
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/satmaelstorm/allcache"
+)
+
+const (
+	mqQueues = 8
+	// samples - number of sampled entries of sampled policies, Redis default
+	samples = 5
+	seed    = 1
+)
+
+func sizeOf(size uint64) uint64 {
+	return size
+}
+
+func calcSize(weighted bool) allcache.SizeCalculator[uint64] {
+	if weighted {
+		return sizeOf
+	}
+	return nil
+}
+
+// fraction - part of capacity, but at least one slot
+func fraction(capacity uint64, percent uint64) uint64 {
+	r := capacity * percent / 100
+	if r < 1 {
+		return 1
+	}
+	return r
+}
+
+// Policies - all policies of allcache with parameters recommended by their papers
+func Policies() []Policy {
+	return []Policy{
+		{
+			Name: "lru",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewLRU[string, uint64](capacity, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "lru2",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewLRUK[string, uint64](2, capacity, capacity, 0, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "slru",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				probation := fraction(capacity, 20)
+				return allcache.NewSLRU[string, uint64](probation, capacity-probation, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "s2q",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				if weighted {
+					return nil, ErrWeightedNotSupported
+				}
+				a1 := fraction(capacity, 25)
+				return allcache.NewSimplified2Q[string, uint64](capacity-a1, a1), nil
+			},
+		},
+		{
+			Name: "2q",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				if weighted {
+					return nil, ErrWeightedNotSupported
+				}
+				a1In := fraction(capacity, 25)
+				return allcache.NewFull2Q[string, uint64](capacity-a1In, a1In, fraction(capacity, 50)), nil
+			},
+		},
+		{
+			Name: "2qa",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				if weighted {
+					return nil, ErrWeightedNotSupported
+				}
+				return allcache.NewAdaptiveFull2Q[string, uint64](capacity), nil
+			},
+		},
+		{
+			Name: "mq",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewMQCache[string, uint64](
+					mqQueues,
+					capacity,
+					4*capacity,
+					capacity,
+					nil,
+					calcSize(weighted),
+				), nil
+			},
+		},
+		{
+			Name: "mqa",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewAdaptiveMQCache[string, uint64](
+					mqQueues,
+					capacity,
+					4*capacity,
+					capacity,
+					nil,
+					calcSize(weighted),
+				), nil
+			},
+		},
+		{
+			Name: "lirs",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewLIRS[string, uint64](capacity, fraction(capacity, 1), 2*capacity, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "clock",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewClock[string, uint64](capacity, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "clockpro",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				if weighted {
+					return nil, ErrWeightedNotSupported
+				}
+				return allcache.NewClockPro[string, uint64](capacity), nil
+			},
+		},
+		{
+			Name: "s3fifo",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewS3FIFO[string, uint64](capacity, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "sieve",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewSieve[string, uint64](capacity, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "gdsf",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewGDSF[string, uint64](capacity, calcSize(weighted), nil), nil
+			},
+		},
+		{
+			Name: "random",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewRandom[string, uint64](capacity, seed, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "sampledlru",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewSampledLRU[string, uint64](capacity, samples, seed, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "sampledlfu",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				return allcache.NewSampledLFU[string, uint64](capacity, samples, seed, calcSize(weighted)), nil
+			},
+		},
+		{
+			Name: "lfu",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				if weighted {
+					return nil, ErrWeightedNotSupported
+				}
+				return allcache.NewLFU[string, uint64](int(capacity)), nil
+			},
+		},
+		{
+			Name: "lfuda",
+			New: func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error) {
+				if weighted {
+					return nil, ErrWeightedNotSupported
+				}
+				return allcache.NewLFUWithAging[string, uint64](int(capacity), allcache.LFUDynamicAging, 0), nil
+			},
+		},
+	}
+}
+
+// PolicyByName - find policy in Policies by name, case insensitive
+func PolicyByName(name string) (Policy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, p := range Policies() {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Policy{}, fmt.Errorf("unknown policy %q", name)
+}
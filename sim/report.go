@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+var reportHeader = []string{
+	"policy",
+	"capacity",
+	"requests",
+	"hit_ratio",
+	"byte_hit_ratio",
+	"evictions",
+	"ops_per_sec",
+}
+
+// gapHeader - column of gap from OPT, it is written if any result has OPT hit ratio
+const gapHeader = "opt_gap"
+
+func header(results []Result) []string {
+	for _, r := range results {
+		if r.HasOpt {
+			return append(append([]string(nil), reportHeader...), gapHeader)
+		}
+	}
+	return reportHeader
+}
+
+func (r Result) record(withGap bool) []string {
+	result := []string{
+		r.Policy,
+		strconv.FormatUint(r.Capacity, 10),
+		strconv.FormatUint(r.Requests, 10),
+		strconv.FormatFloat(r.HitRatio(), 'f', 4, 64),
+		strconv.FormatFloat(r.ByteHitRatio(), 'f', 4, 64),
+		strconv.FormatUint(r.Evictions, 10),
+		strconv.FormatFloat(r.Throughput(), 'f', 0, 64),
+	}
+	if !withGap {
+		return result
+	}
+	if !r.HasOpt {
+		return append(result, "")
+	}
+	return append(result, strconv.FormatFloat(r.Gap(), 'f', 4, 64))
+}
+
+// WriteTable - write results as aligned text table
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	h := header(results)
+	for _, col := range h {
+		if _, err := fmt.Fprintf(tw, "%s\t", col); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(tw); err != nil {
+		return err
+	}
+	for _, r := range results {
+		for _, col := range r.record(len(h) > len(reportHeader)) {
+			if _, err := fmt.Fprintf(tw, "%s\t", col); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(tw); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteCSV - write results as CSV with header
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	h := header(results)
+	if err := cw.Write(h); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write(r.record(len(h) > len(reportHeader))); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
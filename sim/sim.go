@@ -0,0 +1,144 @@
+package sim
+
+import (
+	"errors"
+	"time"
+
+	"github.com/satmaelstorm/allcache"
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+var ErrWeightedNotSupported = errors.New("policy does not support weighted capacity")
+
+// Factory - create cache with given capacity, if weighted is true capacity is measured in bytes
+type Factory func(capacity uint64, weighted bool) (allcache.Cache[string, uint64], error)
+
+// Policy - named cache factory for replay
+type Policy struct {
+	Name string
+	New  Factory
+}
+
+// Result - replay statistic of one policy with one capacity
+type Result struct {
+	Policy    string
+	Capacity  uint64
+	Requests  uint64
+	Hits      uint64
+	Bytes     uint64
+	HitBytes  uint64
+	Evictions uint64
+	Duration  time.Duration
+	// OptHitRatio - hit ratio of OPT with the same capacity, valid if HasOpt is true
+	OptHitRatio float64
+	HasOpt      bool
+}
+
+func (r Result) HitRatio() float64 {
+	if 0 == r.Requests {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Requests)
+}
+
+func (r Result) ByteHitRatio() float64 {
+	if 0 == r.Bytes {
+		return 0
+	}
+	return float64(r.HitBytes) / float64(r.Bytes)
+}
+
+// Gap - how much hit ratio is less than hit ratio of OPT, valid if HasOpt is true
+func (r Result) Gap() float64 {
+	return r.OptHitRatio - r.HitRatio()
+}
+
+// SetGaps - set hit ratio of OPT results to results with the same capacity
+func SetGaps(results []Result, opt []Result) {
+	for i := range results {
+		for _, o := range opt {
+			if o.Capacity == results[i].Capacity {
+				results[i].OptHitRatio = o.HitRatio()
+				results[i].HasOpt = true
+			}
+		}
+	}
+}
+
+// Throughput - requests per second
+func (r Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// statsCache - caches built on allcache.Store count their evictions
+type statsCache interface {
+	Stats() allcache.Stats
+}
+
+// Replay - replay trace against read-through cache: every miss is followed by Put,
+// delete requests remove the key and are not counted as requests.
+// If weighted is true, size of the object is used as its weight and objects larger
+// than capacity are never admitted.
+func Replay(requests []trace.Request, policy Policy, capacity uint64, weighted bool) (Result, error) {
+	res := Result{Policy: policy.Name, Capacity: capacity}
+	cache, err := policy.New(capacity, weighted)
+	if err != nil {
+		return res, err
+	}
+
+	_, counted := cache.(statsCache)
+	deleter, _ := cache.(allcache.BulkDeleter[string, uint64])
+	inserts, deleted := uint64(0), uint64(0)
+	start := time.Now()
+	for _, a := range requests {
+		if trace.OpDelete == a.Op {
+			if !counted && deleter != nil {
+				deleted += uint64(deleter.DeleteFunc(func(key string, _ uint64) bool { return key == a.Key }))
+			} else {
+				cache.Delete(a.Key)
+			}
+			continue
+		}
+		res.Requests += 1
+		res.Bytes += a.Size
+		if _, ok := cache.Get(a.Key, 0); ok {
+			res.Hits += 1
+			res.HitBytes += a.Size
+			continue
+		}
+		if weighted && a.Size > capacity {
+			continue
+		}
+		cache.Put(a.Key, a.Size)
+		inserts += 1
+	}
+	res.Duration = time.Since(start)
+
+	res.Evictions = evictions(cache, inserts, deleted)
+	return res, nil
+}
+
+// evictions - evictions counted by the store, other caches of allcache admit every put of Replay,
+// so their evicted keys are inserted keys, which are neither deleted nor resident.
+// Resident keys are counted by DeleteFunc, which doesn't change state of the policy.
+func evictions(cache allcache.Cache[string, uint64], inserts, deleted uint64) uint64 {
+	if c, ok := cache.(statsCache); ok {
+		return c.Stats().Evictions
+	}
+	deleter, ok := cache.(allcache.BulkDeleter[string, uint64])
+	if !ok {
+		return 0
+	}
+	resident := uint64(0)
+	deleter.DeleteFunc(func(string, uint64) bool {
+		resident += 1
+		return false
+	})
+	if inserts < deleted+resident {
+		return 0
+	}
+	return inserts - deleted - resident
+}
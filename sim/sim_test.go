@@ -0,0 +1,174 @@
+package sim
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/satmaelstorm/allcache"
+	"github.com/satmaelstorm/allcache/trace"
+	"github.com/satmaelstorm/allcache/workload"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteSim struct {
+	suite.Suite
+	requests []trace.Request
+}
+
+func TestSim(t *testing.T) {
+	suite.Run(t, new(suiteSim))
+}
+
+func (s *suiteSim) SetupTest() {
+	requests, err := trace.ReadAll(trace.NewLIRSReader(strings.NewReader("a 10\nb 20\na 10\nc 40\na 10\nb 20\n")))
+	s.Require().NoError(err)
+	s.requests = requests
+}
+
+func (s *suiteSim) TestReplayLRU() {
+	p, err := PolicyByName("LRU")
+	s.Require().NoError(err)
+
+	r, err := Replay(s.requests, p, 2, false)
+	s.Require().NoError(err)
+	s.Equal(uint64(6), r.Requests)
+	s.Equal(uint64(2), r.Hits)
+	s.Equal(uint64(2), r.Evictions)
+	s.Equal(uint64(110), r.Bytes)
+	s.Equal(uint64(20), r.HitBytes)
+	s.InDelta(2.0/6.0, r.HitRatio(), 1e-9)
+	s.InDelta(20.0/110.0, r.ByteHitRatio(), 1e-9)
+}
+
+func (s *suiteSim) TestReplayWeighted() {
+	p, err := PolicyByName("lru")
+	s.Require().NoError(err)
+
+	r, err := Replay(s.requests, p, 30, true)
+	s.Require().NoError(err)
+	// c is larger than cache and never admitted, a and b fit together
+	s.Equal(uint64(3), r.Hits)
+	s.Equal(uint64(0), r.Evictions)
+
+	p, err = PolicyByName("2q")
+	s.Require().NoError(err)
+	_, err = Replay(s.requests, p, 25, true)
+	s.ErrorIs(err, ErrWeightedNotSupported)
+}
+
+func (s *suiteSim) TestAllPolicies() {
+	for _, p := range Policies() {
+		r, err := Replay(s.requests, p, 4, false)
+		s.Require().NoError(err, p.Name)
+		s.Equal(uint64(3), r.Hits, p.Name)
+		s.Equal(uint64(0), r.Evictions, p.Name)
+	}
+	_, err := PolicyByName("fifo")
+	s.Error(err)
+}
+
+func (s *suiteSim) TestReplayDelete() {
+	p, err := PolicyByName("lru")
+	s.Require().NoError(err)
+
+	requests := append(s.requests, trace.Request{Key: "a", Op: trace.OpDelete}, trace.Request{Key: "a", Size: 10})
+	r, err := Replay(requests, p, 2, false)
+	s.Require().NoError(err)
+	s.Equal(uint64(7), r.Requests)
+	s.Equal(uint64(2), r.Hits)
+	s.Equal(uint64(2), r.Evictions)
+}
+
+func (s *suiteSim) TestOPT() {
+	r := OPT(s.requests, 2, false)
+	s.Equal(OptName, r.Policy)
+	s.Equal(uint64(6), r.Requests)
+	//c is never requested again and isn't cached
+	s.Equal(uint64(3), r.Hits)
+	s.Equal(uint64(0), r.Evictions)
+
+	//b is requested later than a, so it isn't cached instead of a
+	r = OPT(s.requests, 1, false)
+	s.Equal(uint64(2), r.Hits)
+	s.Equal(uint64(0), r.Evictions)
+
+	r = OPT(s.requests, 30, true)
+	s.Equal(uint64(3), r.Hits)
+	s.Equal(uint64(40), r.HitBytes)
+
+	requests := append(s.requests[:3:3], trace.Request{Key: "a", Op: trace.OpDelete}, trace.Request{Key: "a", Size: 10})
+	r = OPT(requests, 2, false)
+	s.Equal(uint64(4), r.Requests)
+	s.Equal(uint64(1), r.Hits)
+}
+
+func (s *suiteSim) TestOPTIsUpperBound() {
+	requests, err := trace.ReadAll(workload.Reader(workload.NewZipf(1, 5000, 0.8), 50000))
+	s.Require().NoError(err)
+	for _, weighted := range []bool{false, true} {
+		opt := OPT(requests, 500, weighted)
+		results := make([]Result, 0)
+		for _, p := range Policies() {
+			r, err := Replay(requests, p, 500, weighted)
+			if err == ErrWeightedNotSupported {
+				continue
+			}
+			s.Require().NoError(err, p.Name)
+			results = append(results, r)
+		}
+		SetGaps(results, []Result{opt})
+		for _, r := range results {
+			s.True(r.HasOpt)
+			s.Greater(r.Gap(), 0.0, r.Policy)
+		}
+	}
+}
+
+func (s *suiteSim) TestReport() {
+	results := []Result{{Policy: "lru", Capacity: 2, Requests: 4, Hits: 1, Bytes: 10, HitBytes: 5}}
+
+	buf := new(bytes.Buffer)
+	s.NoError(WriteCSV(buf, results))
+	s.Equal(
+		"policy,capacity,requests,hit_ratio,byte_hit_ratio,evictions,ops_per_sec\nlru,2,4,0.2500,0.5000,0,0\n",
+		buf.String(),
+	)
+
+	buf.Reset()
+	s.NoError(WriteTable(buf, results))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	s.Len(lines, 2)
+	s.Contains(lines[1], "0.2500")
+
+	opt := Result{Policy: OptName, Capacity: 2, Requests: 4, Hits: 3}
+	results = append([]Result{opt}, results...)
+	SetGaps(results[1:], results[:1])
+	buf.Reset()
+	s.NoError(WriteCSV(buf, results))
+	s.Equal(
+		"policy,capacity,requests,hit_ratio,byte_hit_ratio,evictions,ops_per_sec,opt_gap\n"+
+			"opt,2,4,0.7500,0.0000,0,0,\n"+
+			"lru,2,4,0.2500,0.5000,0,0,0.5000\n",
+		buf.String(),
+	)
+}
+
+func (s *suiteSim) TestEvictionsOfStore() {
+	var store *allcache.Store[string, uint64]
+	p := Policy{
+		Name: "lru-doorkeeper",
+		New: func(capacity uint64, _ bool) (allcache.Cache[string, uint64], error) {
+			store = allcache.NewStore[string, uint64](allcache.NewLRUPolicy[string](), capacity, nil).
+				WithAdmitter(allcache.NewDoorkeeperAdmitter[string](100, nil))
+			return store, nil
+		},
+	}
+	r, err := Replay(s.requests, p, 1, false)
+	s.Require().NoError(err)
+	stats := store.Stats()
+	s.Equal(stats.Evictions, r.Evictions)
+	s.Greater(stats.Rejections, uint64(0))
+	//replay doesn't read the cache after the trace
+	s.Equal(r.Requests, stats.Hits+stats.Misses)
+}
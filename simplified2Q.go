@@ -1,134 +1,99 @@
 package allcache
 
-import (
-	"github.com/satmaelstorm/list"
-	"sync"
-)
+import "github.com/satmaelstorm/list"
 
 // Simplified2Q - simplified2Q @see http://www.vldb.org/conf/1994/P439.PDF
 type Simplified2Q[K comparable, T any] struct {
-	cache *ntsSimplified2Q[K, T]
-	lock  sync.Mutex
+	*Store[K, T]
 }
 
 func NewSimplified2Q[K comparable, T any](amSize, a1Size uint64) Cache[K, T] {
-	cache := new(Simplified2Q[K, T])
-	cache.cache = newNtsSimplified2Q[K, T](amSize, a1Size)
-	return cache
+	return &Simplified2Q[K, T]{NewStore[K, T](NewSimplified2QPolicy[K](amSize, a1Size), amSize+a1Size, nil)}
 }
 
-func (c *Simplified2Q[K, T]) Put(key K, item T) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.cache.put(key, item)
+// NewSimplified2QPolicy - simplified 2Q policy for Store with capacity amSize+a1Size, weights are ignored
+func NewSimplified2QPolicy[K comparable](amSize, a1Size uint64) Policy[K] {
+	return newNtsSimplified2Q[K](amSize, a1Size)
 }
 
-func (c *Simplified2Q[K, T]) Get(key K, def T) (T, bool) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.cache.get(key, def)
+// non thread safe Simplified 2Q
+// @see http://www.vldb.org/conf/1994/P439.PDF
+type ntsSimplified2Q[K comparable] struct {
+	heldKeys[K]
+	items  map[K]*list.Node[cacheEntry2Q[K]]
+	am     *list.Queue[cacheEntry2Q[K]]
+	a1     *list.Queue[cacheEntry2Q[K]]
+	a1Size uint64
+	amSize uint64
 }
 
-func (c *Simplified2Q[K, T]) Delete(key K) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.cache.delete(key)
+func newNtsSimplified2Q[K comparable](amSize, a1Size uint64) *ntsSimplified2Q[K] {
+	return &ntsSimplified2Q[K]{
+		heldKeys: make(heldKeys[K]),
+		items:    make(map[K]*list.Node[cacheEntry2Q[K]], a1Size+amSize),
+		am:       list.NewQueue[cacheEntry2Q[K]](),
+		a1:       list.NewQueue[cacheEntry2Q[K]](),
+		a1Size:   a1Size,
+		amSize:   amSize,
+	}
 }
 
-//non thread safe Simplified 2Q
-//@see http://www.vldb.org/conf/1994/P439.PDF
-type ntsSimplified2Q[K comparable, T any] struct {
-	items     map[K]*list.Node[cacheEntry2Q[K, T]]
-	am        *list.Queue[cacheEntry2Q[K, T]]
-	a1        *list.Queue[cacheEntry2Q[K, T]]
-	a1Size    uint64
-	amSize    uint64
-	totalSize uint64
+// OnInsert - new key goes to A1, replaced value is a hit
+func (c *ntsSimplified2Q[K]) OnInsert(key K, _ uint64) {
+	if _, ok := c.items[key]; ok {
+		c.OnAccess(key)
+		return
+	}
+	c.a1.Enqueue(cacheEntry2Q[K]{key: key})
+	c.items[key] = c.a1.Tail()
 }
 
-func newNtsSimplified2Q[K comparable, T any](amSize, a1Size uint64) *ntsSimplified2Q[K, T] {
-	return &ntsSimplified2Q[K, T]{
-		items:     make(map[K]*list.Node[cacheEntry2Q[K, T]], a1Size+amSize),
-		am:        list.NewQueue[cacheEntry2Q[K, T]](),
-		a1:        list.NewQueue[cacheEntry2Q[K, T]](),
-		a1Size:    a1Size,
-		amSize:    amSize,
-		totalSize: a1Size + amSize,
+func (c *ntsSimplified2Q[K]) OnAccess(key K) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	if e.Value().isAm {
+		c.am.MoveToBack(e)
+		return
 	}
+	c.a1.Remove(e)
+	c.am.Enqueue(cacheEntry2Q[K]{key: key, isAm: true})
+	c.items[key] = c.am.Tail()
 }
 
-func (c *ntsSimplified2Q[K, T]) get(key K, def T) (T, bool) {
+func (c *ntsSimplified2Q[K]) OnRemove(key K, _ RemoveReason) {
 	if e, ok := c.items[key]; ok {
+		delete(c.items, key)
+		delete(c.heldKeys, key)
 		if e.Value().isAm {
-			c.am.MoveToBack(e)
+			c.am.Remove(e)
 		} else {
 			c.a1.Remove(e)
-			cacheEntry := e.Value()
-			cacheEntry.isAm = true
-			e.SetValue(cacheEntry)
-			c.am.Enqueue(cacheEntry)
-			c.items[key] = c.am.Tail()
 		}
-		return e.Value().value, true
 	}
-	return def, false
 }
 
-func (c *ntsSimplified2Q[K, T]) put(key K, value T) {
-	if e, ok := c.items[key]; ok {
-		cacheEntry := e.Value()
-		cacheEntry.value = value
-		if cacheEntry.isAm {
-			e.SetValue(cacheEntry)
-			c.am.MoveToBack(e)
-		} else {
-			c.a1.Remove(e)
-			cacheEntry.isAm = true
-			e.SetValue(cacheEntry)
-			c.am.Enqueue(cacheEntry)
-			c.items[key] = c.am.Tail()
-		}
-		return
-	}
-
-	cacheEntry := cacheEntry2Q[K, T]{isAm: false}
-	cacheEntry.key = key
-	cacheEntry.value = value
-
-	defer func() {
-		c.a1.Enqueue(cacheEntry)
-		c.items[key] = c.a1.Tail()
-	}()
-
-	if c.totalSize > uint64(c.a1.Len()+c.am.Len()) {
-		return
-	}
-
+// Victim - head of A1 if A1 is full, head of Am otherwise, held keys are skipped
+func (c *ntsSimplified2Q[K]) Victim() (K, bool) {
 	if c.a1Size <= uint64(c.a1.Len()) {
-		e := c.a1.Dequeue()
-		if e != nil {
-			delete(c.items, e.Value().key)
+		if key, ok := c.first2Q(c.a1); ok {
+			return key, true
 		}
-		return
 	}
-
-	e := c.am.Dequeue()
-	if e != nil {
-		delete(c.items, e.Value().key)
+	if key, ok := c.first2Q(c.am); ok {
+		return key, true
 	}
+	return c.first2Q(c.a1)
 }
 
-func (c *ntsSimplified2Q[K, T]) delete(key K) {
-	if e, ok := c.items[key]; ok {
-		c.remove(e)
-	}
-}
-
-func (c *ntsSimplified2Q[K, T]) remove(e *list.Node[cacheEntry2Q[K, T]]) {
-	delete(c.items, e.Value().key)
-	if e.Value().isAm {
-		c.am.Remove(e)
-	} else {
-		c.a1.Remove(e)
+// first2Q - the first key of 2Q queue, which is not held
+func (h heldKeys[K]) first2Q(q *list.Queue[cacheEntry2Q[K]]) (K, bool) {
+	for e := q.Head(); e != nil; e = e.Next() {
+		if !h.isHeld(e.Value().key) {
+			return e.Value().key, true
+		}
 	}
+	var zero K
+	return zero, false
 }
@@ -7,7 +7,8 @@ import (
 
 type suiteNtsSimplified2Q struct {
 	suite.Suite
-	cache *ntsSimplified2Q[string, int]
+	cache  *ntsStore[string, int]
+	policy *ntsSimplified2Q[string]
 }
 
 func TestNtsSimplified2Q(t *testing.T) {
@@ -15,7 +16,8 @@ func TestNtsSimplified2Q(t *testing.T) {
 }
 
 func (s *suiteNtsSimplified2Q) SetupTest() {
-	s.cache = newNtsSimplified2Q[string, int](3, 2)
+	s.policy = newNtsSimplified2Q[string](3, 2)
+	s.cache = newNtsStore[string, int](s.policy, 5, nil)
 	s.cache.put("1", 1)
 	s.cache.get("1", 0)
 	s.cache.put("2", 2)
@@ -0,0 +1,67 @@
+package allcache
+
+const (
+	sketchDepth    = 4
+	sketchMaxCount = 15
+	// sketchSamplesPerCounter - counters are halved after width * sketchSamplesPerCounter increments
+	sketchSamplesPerCounter = 10
+)
+
+// countMinSketch - approximate frequencies of keys with 4 bit counters (kept in bytes for simplicity),
+// all counters are halved periodically, so old popularity fades @see https://arxiv.org/abs/1512.00727
+type countMinSketch struct {
+	rows       [sketchDepth][]uint8
+	mask       uint64
+	samples    uint64
+	sampleSize uint64
+}
+
+// newCountMinSketch - width of rows is the next power of two of capacity
+func newCountMinSketch(capacity uint64) *countMinSketch {
+	width := uint64(1)
+	for width < capacity {
+		width <<= 1
+	}
+	s := &countMinSketch{mask: width - 1, sampleSize: width * sketchSamplesPerCounter}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// index - row i uses its own half of hash by double hashing
+func (s *countMinSketch) index(h uint64, i int) uint64 {
+	return (h + uint64(i)*(h>>32|1)) & s.mask
+}
+
+func (s *countMinSketch) increment(h uint64) {
+	for i := range s.rows {
+		j := s.index(h, i)
+		if s.rows[i][j] < sketchMaxCount {
+			s.rows[i][j] += 1
+		}
+	}
+	s.samples += 1
+	if s.samples >= s.sampleSize {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch) estimate(h uint64) uint8 {
+	r := uint8(sketchMaxCount)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(h, i)]; c < r {
+			r = c
+		}
+	}
+	return r
+}
+
+func (s *countMinSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.samples /= 2
+}
@@ -0,0 +1,187 @@
+package allcache
+
+import (
+	"github.com/satmaelstorm/list"
+	"sync"
+)
+
+// SLRU - Segmented LRU: new entries go to probation segment, hits promote them to protected segment,
+// entries out of protected segment are demoted to the MRU end of probation segment and get one more chance.
+// Probation segment takes at least probationSize and the space which is not used by protected segment.
+type SLRU[K comparable, T any] struct {
+	cache *ntsSLRU[K, T]
+	lock  sync.Mutex
+}
+
+// NewSLRU - items larger than probationSize are not cached, items larger than protectedSize are never promoted
+func NewSLRU[K comparable, T any](probationSize, protectedSize uint64, calcSize SizeCalculator[T]) Cache[K, T] {
+	cache := new(SLRU[K, T])
+	cache.cache = newNtsSLRU[K, T](probationSize, protectedSize, calcSize)
+	return cache
+}
+
+func (c *SLRU[K, T]) Put(key K, item T) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.put(key, item)
+}
+
+func (c *SLRU[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.get(key, def)
+}
+
+func (c *SLRU[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache.delete(key)
+}
+
+func (c *SLRU[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.cache.deleteFunc(f)
+}
+
+// non thread safe SLRU, LRU end of both segments is the head of the queue
+type ntsSLRU[K comparable, T any] struct {
+	items     map[K]*list.Node[*cacheEntrySLRU[K, T]]
+	probation *list.Queue[*cacheEntrySLRU[K, T]]
+	protected *list.Queue[*cacheEntrySLRU[K, T]]
+
+	probationSize uint64
+	protectedSize uint64
+
+	probationWeight uint64
+	protectedWeight uint64
+
+	sizeCalc SizeCalculator[T]
+}
+
+func newNtsSLRU[K comparable, T any](probationSize, protectedSize uint64, sizeCalc SizeCalculator[T]) *ntsSLRU[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	return &ntsSLRU[K, T]{
+		items:     make(map[K]*list.Node[*cacheEntrySLRU[K, T]], probationSize+protectedSize),
+		probation: list.NewQueue[*cacheEntrySLRU[K, T]](),
+		protected: list.NewQueue[*cacheEntrySLRU[K, T]](),
+
+		probationSize: probationSize,
+		protectedSize: protectedSize,
+
+		sizeCalc: sizeCalc,
+	}
+}
+
+func (c *ntsSLRU[K, T]) get(key K, def T) (T, bool) {
+	if e, ok := c.items[key]; ok {
+		c.hit(e)
+		return e.Value().value, true
+	}
+	return def, false
+}
+
+func (c *ntsSLRU[K, T]) put(key K, value T) {
+	size := c.sizeCalc(value)
+	if size > c.probationSize {
+		c.delete(key)
+		return
+	}
+	if e, ok := c.items[key]; ok {
+		entry := e.Value()
+		if entry.isProtected {
+			c.protectedWeight += size - entry.size
+		} else {
+			c.probationWeight += size - entry.size
+		}
+		entry.value = value
+		entry.size = size
+		c.hit(e)
+		return
+	}
+	entry := &cacheEntrySLRU[K, T]{size: size}
+	entry.key = key
+	entry.value = value
+	c.toProbation(entry)
+	c.reclaim()
+}
+
+func (c *ntsSLRU[K, T]) delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+func (c *ntsSLRU[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.Value().value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.delete(k)
+	}
+	return len(keys)
+}
+
+// hit - entry goes to MRU end of protected segment
+func (c *ntsSLRU[K, T]) hit(e *list.Node[*cacheEntrySLRU[K, T]]) {
+	entry := e.Value()
+	if entry.isProtected {
+		c.protected.MoveToBack(e)
+	} else if entry.size > c.protectedSize {
+		c.probation.MoveToBack(e)
+	} else {
+		c.probation.Remove(e)
+		c.probationWeight -= entry.size
+		entry.isProtected = true
+		c.protected.Enqueue(entry)
+		c.protectedWeight += entry.size
+		c.items[entry.key] = c.protected.Tail()
+	}
+	c.reclaim()
+}
+
+// reclaim - demote LRU entries of protected segment to probation segment,
+// then evict LRU entries of probation segment while cache is overflowed
+func (c *ntsSLRU[K, T]) reclaim() {
+	for c.protectedWeight > c.protectedSize {
+		e := c.protected.Head()
+		if nil == e {
+			break
+		}
+		c.protected.Remove(e)
+		entry := e.Value()
+		c.protectedWeight -= entry.size
+		c.toProbation(entry)
+	}
+	for c.probationWeight+c.protectedWeight > c.probationSize+c.protectedSize {
+		e := c.probation.Head()
+		if nil == e {
+			return
+		}
+		c.remove(e)
+	}
+}
+
+func (c *ntsSLRU[K, T]) toProbation(entry *cacheEntrySLRU[K, T]) {
+	entry.isProtected = false
+	c.probation.Enqueue(entry)
+	c.probationWeight += entry.size
+	c.items[entry.key] = c.probation.Tail()
+}
+
+func (c *ntsSLRU[K, T]) remove(e *list.Node[*cacheEntrySLRU[K, T]]) {
+	entry := e.Value()
+	if entry.isProtected {
+		c.protected.Remove(e)
+		c.protectedWeight -= entry.size
+	} else {
+		c.probation.Remove(e)
+		c.probationWeight -= entry.size
+	}
+	delete(c.items, entry.key)
+}
@@ -0,0 +1,130 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+)
+
+type suiteNtsSLRU struct {
+	suite.Suite
+	cache *ntsSLRU[string, int]
+}
+
+func TestNtsSLRU(t *testing.T) {
+	suite.Run(t, new(suiteNtsSLRU))
+}
+
+func (s *suiteNtsSLRU) SetupTest() {
+	s.cache = newNtsSLRU[string, int](3, 2, nil)
+	for i := 1; i <= 3; i++ {
+		s.cache.put(strconv.Itoa(i), i)
+	}
+}
+
+func (s *suiteNtsSLRU) TestProbation() {
+	//probation segment takes space of empty protected segment
+	for i := 4; i <= 6; i++ {
+		s.cache.put(strconv.Itoa(i), i)
+	}
+	_, ok := s.cache.get("1", 0)
+	s.False(ok)
+	s.Equal(5, s.cache.probation.Len())
+	s.Equal(0, s.cache.protected.Len())
+}
+
+func (s *suiteNtsSLRU) TestPromote() {
+	s.cache.get("1", 0)
+	s.True(s.cache.items["1"].Value().isProtected)
+	s.Equal(uint64(1), s.cache.protectedWeight)
+	s.Equal(uint64(2), s.cache.probationWeight)
+
+	//1 is protected from eviction from probation
+	for i := 4; i <= 7; i++ {
+		s.cache.put(strconv.Itoa(i), i)
+	}
+	_, ok := s.cache.get("1", 0)
+	s.True(ok)
+	for i := 2; i <= 3; i++ {
+		_, ok = s.cache.get(strconv.Itoa(i), 0)
+		s.False(ok, i)
+	}
+}
+
+func (s *suiteNtsSLRU) TestDemote() {
+	s.cache.get("1", 0)
+	s.cache.get("2", 0)
+	s.cache.get("3", 0)
+	//1 is demoted to MRU end of probation
+	s.False(s.cache.items["1"].Value().isProtected)
+	s.Equal("1", s.cache.probation.Tail().Value().key)
+	s.Equal(uint64(2), s.cache.protectedWeight)
+
+	s.cache.put("4", 4)
+	s.cache.put("5", 5)
+	s.cache.put("6", 6)
+	//demoted 1 got no hits and became LRU entry of probation
+	_, ok := s.cache.get("1", 0)
+	s.False(ok)
+	for _, k := range []string{"2", "3", "4", "5", "6"} {
+		_, ok = s.cache.get(k, 0)
+		s.True(ok, k)
+	}
+}
+
+func (s *suiteNtsSLRU) TestSizeCalculator() {
+	c := newNtsSLRU[string, int](10, 5, func(v int) uint64 { return uint64(v) })
+	c.put("big", 11)
+	_, ok := c.get("big", 0)
+	s.False(ok)
+
+	//too big for protected segment, stays in probation
+	c.put("a", 6)
+	c.get("a", 0)
+	s.False(c.items["a"].Value().isProtected)
+
+	c.put("b", 4)
+	c.get("b", 0)
+	s.True(c.items["b"].Value().isProtected)
+	c.put("b", 6)
+	//b doesn't fit protected segment any more and is demoted
+	s.False(c.items["b"].Value().isProtected)
+	s.Equal(uint64(12), c.probationWeight)
+	s.Equal(uint64(0), c.protectedWeight)
+
+	c.put("c", 4)
+	_, ok = c.get("a", 0)
+	s.False(ok)
+	s.Equal(uint64(10), c.probationWeight)
+}
+
+func (s *suiteNtsSLRU) TestPutAndDelete() {
+	s.cache.get("1", 0)
+	s.cache.put("1", 10)
+	r, ok := s.cache.get("1", 0)
+	s.True(ok)
+	s.Equal(10, r)
+
+	s.cache.delete("1")
+	s.cache.delete("2")
+	_, ok = s.cache.get("1", 0)
+	s.False(ok)
+	s.Equal(uint64(0), s.cache.protectedWeight)
+	s.Equal(uint64(1), s.cache.probationWeight)
+	s.Len(s.cache.items, 1)
+}
+
+func (s *suiteNtsSLRU) TestTSVersion() {
+	c := NewSLRU[int, int](3, 3, nil)
+	c.Put(1, 1)
+
+	r, ok := c.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+
+	c.Delete(1)
+
+	r, ok = c.Get(1, 0)
+	s.False(ok)
+	s.Equal(0, r)
+}
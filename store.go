@@ -0,0 +1,499 @@
+package allcache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrPinnedOverCapacity - pinned and acquired items take all capacity
+var ErrPinnedOverCapacity = errors.New("weight of pinned items exceeds capacity")
+
+// Stats - counters of Store
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+	// Rejections - new keys which were not admitted by Admitter
+	Rejections uint64
+	// HeldRejections - values which were not cached, because they don't fit with pinned and acquired items
+	HeldRejections uint64
+}
+
+func (s Stats) HitRatio() float64 {
+	if 0 == s.Hits+s.Misses {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Store - cache which keeps values, weights, TTL and statistics, and asks Policy which key to evict
+// when total weight exceeds capacity. Any Policy gets TTL, stats and eviction callbacks for free.
+type Store[K comparable, T any] struct {
+	cache *ntsStore[K, T]
+	lock  sync.Mutex
+}
+
+// NewStore - items larger than capacity are not cached, weight of item is 1 if calcSize is nil
+func NewStore[K comparable, T any](policy Policy[K], capacity uint64, calcSize SizeCalculator[T]) *Store[K, T] {
+	s := new(Store[K, T])
+	s.cache = newNtsStore[K, T](policy, capacity, calcSize)
+	return s
+}
+
+// WithTTL - default TTL of items, 0 means items never expire. Must be called before the store is used.
+// Expired items are removed lazily, when they are read or evicted.
+func (s *Store[K, T]) WithTTL(ttl time.Duration) *Store[K, T] {
+	s.cache.ttl = ttl
+	return s
+}
+
+// WithAdmitter - admission policy consulted before new key is inserted. Must be called before the store is used.
+func (s *Store[K, T]) WithAdmitter(admitter Admitter[K]) *Store[K, T] {
+	s.cache.admitter = admitter
+	return s
+}
+
+// WithEvictionCallback - callback for removed items. Must be called before the store is used.
+func (s *Store[K, T]) WithEvictionCallback(onRemove EvictionCallback[K, T]) *Store[K, T] {
+	s.cache.onRemove = onRemove
+	return s
+}
+
+// Put - put item with default TTL. If the item doesn't fit with pinned and acquired items, it is dropped
+// and counted in Stats.HeldRejections, the old value of the key is kept, use TryPut to get the error.
+func (s *Store[K, T]) Put(key K, item T) {
+	_ = s.TryPut(key, item)
+}
+
+// TryPut - Put which returns ErrPinnedOverCapacity if the item doesn't fit with pinned and acquired items,
+// it is not cached then
+func (s *Store[K, T]) TryPut(key K, item T) error {
+	s.lock.Lock()
+	defer s.unlock()
+	return s.cache.put(key, item)
+}
+
+// PutWithTTL - put item which expires after ttl, 0 means it never expires.
+// ErrPinnedOverCapacity is returned as by TryPut.
+func (s *Store[K, T]) PutWithTTL(key K, item T, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.unlock()
+	return s.cache.putWithTTL(key, item, ttl)
+}
+
+func (s *Store[K, T]) Get(key K, def T) (T, bool) {
+	s.lock.Lock()
+	defer s.unlock()
+	return s.cache.get(key, def)
+}
+
+func (s *Store[K, T]) Delete(key K) {
+	s.lock.Lock()
+	defer s.unlock()
+	s.cache.delete(key)
+}
+
+// Pin - pinned item is never evicted, but it still counts toward weight, expires and can be deleted.
+// False if there is no such item.
+func (s *Store[K, T]) Pin(key K) bool {
+	s.lock.Lock()
+	defer s.unlock()
+	return s.cache.pin(key)
+}
+
+// Unpin - item can be evicted again, false if there is no such pinned item
+func (s *Store[K, T]) Unpin(key K) bool {
+	s.lock.Lock()
+	defer s.unlock()
+	return s.cache.unpin(key)
+}
+
+// DeleteFunc - eviction callback is called for deleted items with RemoveDeleted
+func (s *Store[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	s.lock.Lock()
+	defer s.unlock()
+	return s.cache.deleteFunc(f)
+}
+
+// WithPrefixIndex - keep keys of the store in radix tree, so DeletePrefix doesn't scan all keys.
+// Must be called before the store is used.
+func WithPrefixIndex[T any](s *Store[string, T]) *Store[string, T] {
+	s.cache.index = newRadixTree()
+	return s
+}
+
+// deletePrefix - delete keys with prefix by the radix tree, false if the store has no prefix index
+func (s *Store[K, T]) deletePrefix(prefix string) (int, bool) {
+	s.lock.Lock()
+	defer s.unlock()
+	index, ok := any(s.cache.index).(*radixTree)
+	if !ok {
+		return 0, false
+	}
+	keys := index.keysWithPrefix(prefix)
+	for _, key := range keys {
+		s.cache.remove(any(key).(K), RemoveDeleted)
+	}
+	return len(keys), true
+}
+
+// contains - key is in the store, even if it is expired
+func (s *Store[K, T]) contains(key K) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.cache.items[key]
+	return ok
+}
+
+// Len - number of items, expired items are counted until they are removed
+func (s *Store[K, T]) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.cache.items)
+}
+
+// Weight - total weight of items
+func (s *Store[K, T]) Weight() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cache.weight
+}
+
+func (s *Store[K, T]) Stats() Stats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cache.stats
+}
+
+// unlock - unlock the store and call eviction callback for removed items
+func (s *Store[K, T]) unlock() {
+	removed := s.cache.removed
+	s.cache.removed = nil
+	s.lock.Unlock()
+	for _, r := range removed {
+		s.cache.onRemove(r.key, r.value, r.reason)
+	}
+}
+
+type storeEntry[T any] struct {
+	value  T
+	weight uint64
+	// expire - unix time in nanoseconds, 0 if entry never expires
+	expire int64
+	pinned bool
+	// refs - number of handles of acquired entry
+	refs int
+	// detached - entry was removed from the store while it was acquired, reason is why
+	detached bool
+	reason   RemoveReason
+}
+
+type storeRemoved[K comparable, T any] struct {
+	key    K
+	value  T
+	reason RemoveReason
+}
+
+// non thread safe Store
+type ntsStore[K comparable, T any] struct {
+	items  map[K]*storeEntry[T]
+	policy Policy[K]
+	// holder - policy, if it keeps held keys, nil otherwise
+	holder HoldingPolicy[K]
+
+	weight uint64
+	// heldWeight - weight of pinned and acquired entries, which policy never evicts
+	heldWeight uint64
+	capacity   uint64
+	sizeCalc   SizeCalculator[T]
+
+	ttl time.Duration
+	now func() time.Time
+
+	admitter Admitter[K]
+	// index - optional index of keys
+	index    keyIndex[K]
+	stats    Stats
+	onRemove EvictionCallback[K, T]
+	// removed - items removed under lock, callback is called for them after unlock
+	removed []storeRemoved[K, T]
+}
+
+func newNtsStore[K comparable, T any](policy Policy[K], capacity uint64, sizeCalc SizeCalculator[T]) *ntsStore[K, T] {
+	if nil == sizeCalc {
+		sizeCalc = func(T) uint64 { return 1 }
+	}
+	holder, _ := policy.(HoldingPolicy[K])
+	return &ntsStore[K, T]{
+		items:    make(map[K]*storeEntry[T]),
+		policy:   policy,
+		holder:   holder,
+		capacity: capacity,
+		sizeCalc: sizeCalc,
+		now:      time.Now,
+	}
+}
+
+func (c *ntsStore[K, T]) put(key K, value T) error {
+	return c.putWithTTL(key, value, c.ttl)
+}
+
+func (c *ntsStore[K, T]) putWithTTL(key K, value T, ttl time.Duration) error {
+	weight := c.sizeCalc(value)
+	e, ok := c.items[key]
+	held := ok && e.held()
+	if weight > c.capacity && !held {
+		if ok {
+			c.remove(key, RemoveEvicted)
+		}
+		return nil
+	}
+	heldWeight := c.heldWeight
+	if held {
+		heldWeight -= e.weight
+	}
+	//held item is replaced only by the value which fits
+	if heldWeight+weight > c.capacity {
+		c.stats.HeldRejections += 1
+		return ErrPinnedOverCapacity
+	}
+	expire := int64(0)
+	if ttl > 0 {
+		expire = c.now().Add(ttl).UnixNano()
+	}
+	if ok && e.refs > 0 {
+		c.replaceAcquired(key, e, &storeEntry[T]{value: value, weight: weight, expire: expire, pinned: e.pinned})
+		c.reclaim(0)
+		return nil
+	}
+	if ok {
+		c.weight = c.weight - e.weight + weight
+		if held {
+			c.heldWeight = c.heldWeight - e.weight + weight
+		}
+		if c.inPolicy(e) {
+			c.policy.OnInsert(key, weight)
+		}
+		e.value, e.weight, e.expire = value, weight, expire
+		c.reclaim(0)
+		return nil
+	}
+	if !c.admit(key, weight) {
+		c.stats.Rejections += 1
+		return nil
+	}
+	c.reclaim(weight)
+	c.items[key] = &storeEntry[T]{value: value, weight: weight, expire: expire}
+	c.weight += weight
+	if c.index != nil {
+		c.index.insert(key)
+	}
+	c.policy.OnInsert(key, weight)
+	return nil
+}
+
+// replaceAcquired - handles keep the old entry, it is reported with RemoveReplaced after the last release
+func (c *ntsStore[K, T]) replaceAcquired(key K, e, ne *storeEntry[T]) {
+	c.items[key] = ne
+	c.weight = c.weight - e.weight + ne.weight
+	c.heldWeight -= e.weight
+	e.detached, e.reason = true, RemoveReplaced
+	switch {
+	case ne.pinned:
+		c.heldWeight += ne.weight
+	case c.holder != nil:
+		c.holder.OnUnhold(key)
+	}
+	if c.inPolicy(ne) {
+		c.policy.OnInsert(key, ne.weight)
+	}
+}
+
+func (c *ntsStore[K, T]) get(key K, def T) (T, bool) {
+	if c.admitter != nil {
+		c.admitter.Record(key)
+	}
+	e, ok := c.items[key]
+	if ok && c.isExpired(e) {
+		c.remove(key, RemoveExpired)
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses += 1
+		return def, false
+	}
+	c.stats.Hits += 1
+	if c.inPolicy(e) {
+		c.policy.OnAccess(key)
+	}
+	return e.value, true
+}
+
+func (c *ntsStore[K, T]) delete(key K) {
+	c.remove(key, RemoveDeleted)
+}
+
+// admit - ask admitter about new key, the victim is the first key policy would evict for it
+func (c *ntsStore[K, T]) admit(key K, weight uint64) bool {
+	if nil == c.admitter {
+		return true
+	}
+	var victim K
+	hasVictim := false
+	if c.weight+weight > c.capacity {
+		victim, hasVictim = c.policy.Victim()
+	}
+	return c.admitter.Admit(key, weight, victim, hasVictim)
+}
+
+func (c *ntsStore[K, T]) pin(key K) bool {
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	if !e.held() {
+		c.hold(key, e)
+	}
+	e.pinned = true
+	return true
+}
+
+func (c *ntsStore[K, T]) unpin(key K) bool {
+	e, ok := c.items[key]
+	if !ok || !e.pinned {
+		return false
+	}
+	e.pinned = false
+	if !e.held() {
+		c.unhold(key, e)
+	}
+	return true
+}
+
+func (e *storeEntry[T]) held() bool {
+	return e.pinned || e.refs > 0
+}
+
+// inPolicy - policy tracks the entry, held entry is tracked by HoldingPolicy only
+func (c *ntsStore[K, T]) inPolicy(e *storeEntry[T]) bool {
+	return !e.held() || c.holder != nil
+}
+
+// hold - exempt the entry from eviction, policy which is not HoldingPolicy forgets it
+func (c *ntsStore[K, T]) hold(key K, e *storeEntry[T]) {
+	c.heldWeight += e.weight
+	if c.holder != nil {
+		c.holder.OnHold(key)
+		return
+	}
+	c.policy.OnRemove(key, RemovePinned)
+}
+
+// unhold - the entry can be evicted again, policy which is not HoldingPolicy gets it as new key
+func (c *ntsStore[K, T]) unhold(key K, e *storeEntry[T]) {
+	c.heldWeight -= e.weight
+	if c.holder != nil {
+		c.holder.OnUnhold(key)
+		return
+	}
+	c.policy.OnInsert(key, e.weight)
+}
+
+// heldKeys - pinned and acquired keys of HoldingPolicy, which Victim skips
+type heldKeys[K comparable] map[K]struct{}
+
+func (h heldKeys[K]) OnHold(key K) {
+	h[key] = struct{}{}
+}
+
+func (h heldKeys[K]) OnUnhold(key K) {
+	delete(h, key)
+}
+
+func (h heldKeys[K]) isHeld(key K) bool {
+	_, ok := h[key]
+	return ok
+}
+
+func (c *ntsStore[K, T]) deleteFunc(f func(key K, value T) bool) int {
+	var keys []K
+	for k, e := range c.items {
+		if f(k, e.value) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		c.remove(k, RemoveDeleted)
+	}
+	return len(keys)
+}
+
+func (c *ntsStore[K, T]) isExpired(e *storeEntry[T]) bool {
+	return e.expire != 0 && e.expire <= c.now().UnixNano()
+}
+
+// reclaim - evict victims of the policy until weight fits into capacity.
+// It stops if the policy returns the same victim again and nothing was freed, e.g. a key unknown to Store,
+// which policy doesn't forget by OnRemove.
+func (c *ntsStore[K, T]) reclaim(weight uint64) {
+	var last K
+	lastWeight := c.weight
+	for first := true; c.weight+weight > c.capacity; first = false {
+		key, ok := c.policy.Victim()
+		if !ok || (!first && key == last && c.weight == lastWeight) {
+			return
+		}
+		last, lastWeight = key, c.weight
+		reason := RemoveEvicted
+		if e, ok := c.items[key]; ok {
+			//HoldingPolicy must skip held keys
+			if e.held() {
+				return
+			}
+			if c.isExpired(e) {
+				reason = RemoveExpired
+			}
+		}
+		c.remove(key, reason)
+	}
+}
+
+// remove - policy is notified even about absent key, but not about held one, unless it is HoldingPolicy.
+// Callback for acquired entry is called after the last handle is released.
+func (c *ntsStore[K, T]) remove(key K, reason RemoveReason) {
+	e, ok := c.items[key]
+	if !ok || c.inPolicy(e) {
+		c.policy.OnRemove(key, reason)
+	}
+	if !ok {
+		return
+	}
+	delete(c.items, key)
+	c.weight -= e.weight
+	if e.held() {
+		c.heldWeight -= e.weight
+	}
+	if c.index != nil {
+		c.index.remove(key)
+	}
+	switch reason {
+	case RemoveEvicted:
+		c.stats.Evictions += 1
+	case RemoveExpired:
+		c.stats.Expirations += 1
+	}
+	if e.refs > 0 {
+		e.detached, e.reason = true, reason
+		return
+	}
+	c.removeCallback(key, e.value, reason)
+}
+
+// removeCallback - remember removed item, callback is called for it after unlock
+func (c *ntsStore[K, T]) removeCallback(key K, value T, reason RemoveReason) {
+	if c.onRemove != nil {
+		c.removed = append(c.removed, storeRemoved[K, T]{key: key, value: value, reason: reason})
+	}
+}
@@ -0,0 +1,307 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fifoPolicy - minimal custom policy, the oldest inserted key is the victim
+type fifoPolicy struct {
+	keys    []string
+	removed map[string]RemoveReason
+}
+
+func (p *fifoPolicy) OnInsert(key string, _ uint64) {
+	for _, k := range p.keys {
+		if k == key {
+			return
+		}
+	}
+	p.keys = append(p.keys, key)
+}
+
+func (p *fifoPolicy) OnAccess(string) {}
+
+func (p *fifoPolicy) OnRemove(key string, reason RemoveReason) {
+	p.removed[key] = reason
+	for i, k := range p.keys {
+		if k == key {
+			p.keys = append(p.keys[:i], p.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *fifoPolicy) Victim() (string, bool) {
+	if len(p.keys) == 0 {
+		return "", false
+	}
+	return p.keys[0], true
+}
+
+type suiteNtsStore struct {
+	suite.Suite
+	cache  *ntsStore[string, int]
+	policy *fifoPolicy
+	now    time.Time
+}
+
+func TestNtsStore(t *testing.T) {
+	suite.Run(t, new(suiteNtsStore))
+}
+
+func (s *suiteNtsStore) SetupTest() {
+	s.policy = &fifoPolicy{removed: map[string]RemoveReason{}}
+	s.cache = newNtsStore[string, int](s.policy, 10, func(v int) uint64 { return uint64(v) })
+	s.now = time.Unix(1000, 0)
+	s.cache.now = func() time.Time { return s.now }
+}
+
+func (s *suiteNtsStore) TestCustomPolicy() {
+	s.cache.put("a", 4)
+	s.cache.put("b", 4)
+	s.cache.put("c", 4)
+	_, ok := s.cache.get("a", 0)
+	s.False(ok)
+	s.Equal(RemoveEvicted, s.policy.removed["a"])
+	s.Equal(uint64(8), s.cache.weight)
+
+	//replace changes weight and evicts the oldest key
+	s.cache.put("c", 7)
+	_, ok = s.cache.get("b", 0)
+	s.False(ok)
+	r, ok := s.cache.get("c", 0)
+	s.True(ok)
+	s.Equal(7, r)
+	s.Equal(uint64(7), s.cache.weight)
+	s.Equal(Stats{Hits: 1, Misses: 2, Evictions: 2}, s.cache.stats)
+}
+
+func (s *suiteNtsStore) TestOversized() {
+	s.cache.put("a", 4)
+	s.cache.put("big", 11)
+	_, ok := s.cache.get("big", 0)
+	s.False(ok)
+	s.Equal(uint64(4), s.cache.weight)
+
+	//oversized value removes the old one
+	s.cache.put("a", 11)
+	_, ok = s.cache.get("a", 0)
+	s.False(ok)
+	s.Empty(s.policy.keys)
+	s.Equal(uint64(0), s.cache.weight)
+}
+
+func (s *suiteNtsStore) TestTTL() {
+	s.cache.ttl = time.Minute
+	s.cache.put("a", 1)
+	s.cache.putWithTTL("b", 1, time.Second)
+	s.cache.putWithTTL("c", 1, 0)
+
+	s.now = s.now.Add(2 * time.Second)
+	_, ok := s.cache.get("b", 0)
+	s.False(ok)
+	s.Equal(RemoveExpired, s.policy.removed["b"])
+	_, ok = s.cache.get("a", 0)
+	s.True(ok)
+
+	s.now = s.now.Add(time.Hour)
+	_, ok = s.cache.get("a", 0)
+	s.False(ok)
+	_, ok = s.cache.get("c", 0)
+	s.True(ok)
+	s.Equal(uint64(2), s.cache.stats.Expirations)
+}
+
+func (s *suiteNtsStore) TestExpiredVictim() {
+	s.cache.putWithTTL("a", 5, time.Second)
+	s.cache.put("b", 5)
+	s.now = s.now.Add(2 * time.Second)
+	s.cache.put("c", 5)
+	s.Equal(RemoveExpired, s.policy.removed["a"])
+	s.Equal(Stats{Expirations: 1}, s.cache.stats)
+}
+
+func (s *suiteNtsStore) TestDeleteAbsent() {
+	s.cache.delete("x")
+	_, ok := s.policy.removed["x"]
+	s.True(ok)
+	s.Equal(RemoveDeleted, s.policy.removed["x"])
+}
+
+func (s *suiteNtsStore) TestGhostForgottenOnDelete() {
+	policy := newNtsFull2Q[string](1, 1, 2)
+	c := newNtsStore[string, int](policy, 2, nil)
+	c.put("1", 1)
+	c.put("2", 2)
+	c.put("3", 3)
+	s.Contains(policy.itemsOut, "1")
+	c.delete("1")
+	s.NotContains(policy.itemsOut, "1")
+}
+
+func (s *suiteNtsStore) TestTSVersion() {
+	type removed struct {
+		key    string
+		value  int
+		reason RemoveReason
+	}
+	var got []removed
+	var c *Store[string, int]
+	c = NewStore[string, int](s.policy, 2, nil).WithEvictionCallback(func(key string, value int, reason RemoveReason) {
+		//callback is called outside of the lock
+		c.Len()
+		got = append(got, removed{key, value, reason})
+	})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	c.Delete("b")
+	r, ok := c.Get("c", 0)
+	s.True(ok)
+	s.Equal(3, r)
+	s.Equal([]removed{{"a", 1, RemoveEvicted}, {"b", 2, RemoveDeleted}}, got)
+	s.Equal(1, c.Len())
+	s.Equal(uint64(1), c.Weight())
+	s.Equal(0.5, Stats{Hits: 1, Misses: 1}.HitRatio())
+	s.Equal(Stats{Hits: 1, Evictions: 1}, c.Stats())
+
+	var cache Cache[int, int] = NewStore[int, int](NewLRUPolicy[int](), 3, nil).WithTTL(time.Hour)
+	cache.Put(1, 1)
+	r, ok = cache.Get(1, 0)
+	s.True(ok)
+	s.Equal(1, r)
+}
+
+func (s *suiteNtsStore) TestPin() {
+	s.cache.put("a", 4)
+	s.cache.put("b", 4)
+	s.True(s.cache.pin("a"))
+	s.True(s.cache.pin("a"))
+	s.False(s.cache.pin("x"))
+	s.Equal(RemovePinned, s.policy.removed["a"])
+	s.Equal(uint64(4), s.cache.heldWeight)
+
+	//"a" is the oldest, but pinned
+	s.cache.put("c", 4)
+	_, ok := s.cache.get("a", 0)
+	s.True(ok)
+	_, ok = s.cache.get("b", 0)
+	s.False(ok)
+	s.Equal(uint64(8), s.cache.weight)
+
+	//pinned weight alone exceeds capacity
+	s.True(s.cache.pin("c"))
+	s.ErrorIs(s.cache.put("d", 3), ErrPinnedOverCapacity)
+	_, ok = s.cache.get("d", 0)
+	s.False(ok)
+	s.ErrorIs(s.cache.put("a", 7), ErrPinnedOverCapacity)
+	s.ErrorIs(s.cache.put("a", 11), ErrPinnedOverCapacity)
+	r, _ := s.cache.get("a", 0)
+	s.Equal(4, r)
+	s.Equal(uint64(3), s.cache.stats.HeldRejections)
+	s.NoError(s.cache.put("a", 6))
+	s.Equal(uint64(10), s.cache.heldWeight)
+	s.Empty(s.policy.keys)
+
+	s.True(s.cache.unpin("c"))
+	s.False(s.cache.unpin("c"))
+	s.Equal([]string{"c"}, s.policy.keys)
+	s.NoError(s.cache.put("d", 3))
+	_, ok = s.cache.get("c", 0)
+	s.False(ok)
+
+	//pinned item can be deleted
+	s.cache.delete("a")
+	s.Equal(uint64(0), s.cache.heldWeight)
+	s.Equal(uint64(3), s.cache.weight)
+}
+
+func (s *suiteNtsStore) TestPinPolicies() {
+	policies := map[string]Policy[string]{
+		"lru": NewLRUPolicy[string](),
+		"s2q": NewSimplified2QPolicy[string](3, 2),
+		"2q":  NewFull2QPolicy[string](3, 2, 5),
+		"mq":  NewMQPolicy[string](4, 5, 5, nil),
+		"lfu": NewLFUPolicy[string](LFUDynamicAging, 0),
+	}
+	for name, policy := range policies {
+		c := NewStore[string, int](policy, 5, nil)
+		for i := 0; i < 5; i++ {
+			c.Put(strconv.Itoa(i), i)
+		}
+		s.True(c.Pin("0"), name)
+		s.True(c.Pin("1"), name)
+		for i := 5; i < 100; i++ {
+			c.Put(strconv.Itoa(i), i)
+			c.Get(strconv.Itoa(i-1), 0)
+		}
+		for _, k := range []string{"0", "1"} {
+			_, ok := c.Get(k, 0)
+			s.True(ok, name)
+		}
+		s.Equal(uint64(5), c.Weight(), name)
+
+		//pinned keys stay in the policy, but they are not victims until unpinned
+		c.DeleteFunc(func(key string, _ int) bool { return key != "0" && key != "1" })
+		_, ok := policy.Victim()
+		s.False(ok, name)
+		s.True(c.Unpin("0"), name)
+		victim, ok := policy.Victim()
+		s.True(ok, name)
+		s.Equal("0", victim, name)
+		s.Equal(2, c.Len(), name)
+	}
+}
+
+func (s *suiteNtsStore) TestPinners() {
+	caches := map[string]Cache[string, int]{
+		"lru": NewLRU[string, int](5, nil),
+		"s2q": NewSimplified2Q[string, int](3, 2),
+		"2q":  NewFull2Q[string, int](3, 2, 5),
+		"mq":  NewMQCache[string, int](4, 5, 5, 5, nil, nil),
+		"lfu": NewLFU[string, int](5),
+	}
+	for name, c := range caches {
+		_, ok := c.(Pinner[string])
+		s.True(ok, name)
+	}
+}
+
+func (s *suiteNtsStore) TestPinKeepsHistory() {
+	c := NewStore[string, int](NewLFUPolicy[string](LFUNoAging, 0), 3, nil)
+	c.Put("hot", 1)
+	for i := 0; i < 5; i++ {
+		c.Get("hot", 0)
+	}
+	c.Pin("hot")
+	c.Get("hot", 0)
+	c.Unpin("hot")
+	s.Equal(uint64(7), c.cache.policy.(*ntsLFU[string]).items["hot"].value.freq)
+
+	for i := 0; i < 10; i++ {
+		c.Put(strconv.Itoa(i), i)
+	}
+	_, ok := c.Get("hot", 0)
+	s.True(ok)
+}
+
+// stuckPolicy - broken policy, which returns the key it never forgets
+type stuckPolicy struct {
+	fifoPolicy
+}
+
+func (p *stuckPolicy) Victim() (string, bool) {
+	return "stale", true
+}
+
+func (s *suiteNtsStore) TestStuckVictim() {
+	c := newNtsStore[string, int](&stuckPolicy{fifoPolicy{removed: map[string]RemoveReason{}}}, 2, nil)
+	c.put("a", 1)
+	c.put("b", 2)
+	c.put("c", 3)
+	s.Len(c.items, 3)
+	s.Equal(Stats{}, c.stats)
+}
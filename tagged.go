@@ -0,0 +1,147 @@
+package allcache
+
+import "sync"
+
+// TaggedCache - Store with tags of keys: InvalidateTag deletes every key with the tag.
+// Reverse index of tags is updated by eviction callback of the store, so keys evicted or expired
+// by the store itself are forgotten too.
+type TaggedCache[K comparable, T any] struct {
+	store   *Store[K, T]
+	keyTags map[K][]string
+	tagKeys map[string]map[K]struct{}
+	// lock - guards the index, all calls of the store are made under it, so callbacks of the store are too
+	lock sync.Mutex
+}
+
+// NewTaggedCache - puts, deletes and Acquire of the store must go only through TaggedCache,
+// so its eviction callbacks are called under lock of TaggedCache. Eviction callback of the store is still called.
+func NewTaggedCache[K comparable, T any](store *Store[K, T]) *TaggedCache[K, T] {
+	c := &TaggedCache[K, T]{
+		store:   store,
+		keyTags: make(map[K][]string),
+		tagKeys: make(map[string]map[K]struct{}),
+	}
+	onRemove := store.cache.onRemove
+	store.WithEvictionCallback(func(key K, value T, reason RemoveReason) {
+		//released old value of replaced or re-put key doesn't take tags of the new one
+		if !store.contains(key) {
+			c.forget(key)
+		}
+		if onRemove != nil {
+			onRemove(key, value, reason)
+		}
+	})
+	return c
+}
+
+// Put - put item without tags, old tags of the key are forgotten
+func (c *TaggedCache[K, T]) Put(key K, item T) {
+	c.PutWithTags(key, item)
+}
+
+// PutWithTags - put item with tags, they replace old tags of the key.
+// If the item doesn't fit with pinned items, the old value keeps its tags.
+func (c *TaggedCache[K, T]) PutWithTags(key K, item T, tags ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.store.TryPut(key, item); err != nil {
+		return
+	}
+	c.forget(key)
+	//item could be not admitted or too large
+	if !c.store.contains(key) || 0 == len(tags) {
+		return
+	}
+	c.keyTags[key] = append([]string(nil), tags...)
+	for _, tag := range tags {
+		keys, ok := c.tagKeys[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			c.tagKeys[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+func (c *TaggedCache[K, T]) Get(key K, def T) (T, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.store.Get(key, def)
+}
+
+func (c *TaggedCache[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.store.Delete(key)
+}
+
+func (c *TaggedCache[K, T]) DeleteFunc(f func(key K, value T) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.store.DeleteFunc(f)
+}
+
+// Acquire - Store.Acquire, release of the handle updates the index under lock of TaggedCache
+func (c *TaggedCache[K, T]) Acquire(key K) (Handle[T], bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	h, ok := c.store.Acquire(key)
+	if !ok {
+		return nil, false
+	}
+	return &taggedHandle[K, T]{Handle: h, cache: c}, true
+}
+
+type taggedHandle[K comparable, T any] struct {
+	Handle[T]
+	cache *TaggedCache[K, T]
+}
+
+func (h *taggedHandle[K, T]) Release() {
+	h.cache.lock.Lock()
+	defer h.cache.lock.Unlock()
+	h.Handle.Release()
+}
+
+// InvalidateTag - delete all keys with the tag, returns number of deleted keys
+func (c *TaggedCache[K, T]) InvalidateTag(tag string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	keys := make([]K, 0, len(c.tagKeys[tag]))
+	for key := range c.tagKeys[tag] {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		c.store.Delete(key)
+		//key could be already removed from the store, but not from the index
+		c.forget(key)
+	}
+	return len(keys)
+}
+
+// Tags - tags of the key
+func (c *TaggedCache[K, T]) Tags(key K) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	tags, ok := c.keyTags[key]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), tags...)
+}
+
+// forget - remove the key from the index
+func (c *TaggedCache[K, T]) forget(key K) {
+	tags, ok := c.keyTags[key]
+	if !ok {
+		return
+	}
+	delete(c.keyTags, key)
+	for _, tag := range tags {
+		keys := c.tagKeys[tag]
+		delete(keys, key)
+		if 0 == len(keys) {
+			delete(c.tagKeys, tag)
+		}
+	}
+}
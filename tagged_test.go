@@ -0,0 +1,163 @@
+package allcache
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type suiteTaggedCache struct {
+	suite.Suite
+	cache   *TaggedCache[string, int]
+	removed []string
+}
+
+func TestTaggedCache(t *testing.T) {
+	suite.Run(t, new(suiteTaggedCache))
+}
+
+func (s *suiteTaggedCache) SetupTest() {
+	s.removed = nil
+	store := NewStore[string, int](NewLRUPolicy[string](), 3, nil).
+		WithEvictionCallback(func(key string, _ int, _ RemoveReason) {
+			s.removed = append(s.removed, key)
+		})
+	s.cache = NewTaggedCache[string, int](store)
+}
+
+func (s *suiteTaggedCache) TestInvalidateTag() {
+	s.cache.PutWithTags("a", 1, "row1", "row2")
+	s.cache.PutWithTags("b", 2, "row2")
+	s.cache.Put("c", 3)
+
+	s.Equal(2, s.cache.InvalidateTag("row2"))
+	_, ok := s.cache.Get("a", 0)
+	s.False(ok)
+	_, ok = s.cache.Get("b", 0)
+	s.False(ok)
+	_, ok = s.cache.Get("c", 0)
+	s.True(ok)
+	s.Empty(s.cache.keyTags)
+	s.Empty(s.cache.tagKeys)
+	s.Equal(0, s.cache.InvalidateTag("row1"))
+	//callback of the store is still called
+	s.ElementsMatch([]string{"a", "b"}, s.removed)
+}
+
+func (s *suiteTaggedCache) TestEviction() {
+	s.cache.PutWithTags("a", 1, "row1")
+	s.cache.PutWithTags("b", 2, "row1")
+	s.cache.PutWithTags("c", 3, "row1")
+	s.cache.PutWithTags("d", 4, "row2")
+	s.Equal([]string(nil), s.cache.Tags("a"))
+	s.Len(s.cache.tagKeys["row1"], 2)
+
+	//replace changes tags
+	s.cache.PutWithTags("b", 20, "row2")
+	s.Equal([]string{"row2"}, s.cache.Tags("b"))
+	s.Equal(1, s.cache.InvalidateTag("row1"))
+	s.Equal(2, s.cache.InvalidateTag("row2"))
+	s.Empty(s.cache.keyTags)
+}
+
+func (s *suiteTaggedCache) TestNotCached() {
+	store := NewStore[string, int](NewLRUPolicy[string](), 10, func(v int) uint64 { return uint64(v) }).
+		WithAdmitter(NewSizeAdmitter[string](5))
+	c := NewTaggedCache[string, int](store)
+	c.PutWithTags("large", 6, "t")
+	c.PutWithTags("huge", 11, "t")
+	s.Empty(c.keyTags)
+	s.Empty(c.tagKeys)
+
+	now := time.Unix(1000, 0)
+	store.cache.now = func() time.Time { return now }
+	store.WithTTL(time.Second)
+	c.PutWithTags("a", 1, "t")
+	now = now.Add(time.Minute)
+	_, ok := c.Get("a", 0)
+	s.False(ok)
+	s.Empty(c.keyTags)
+}
+
+func (s *suiteTaggedCache) TestHeldRejection() {
+	store := NewStore[string, int](NewLRUPolicy[string](), 10, func(v int) uint64 { return uint64(v) })
+	c := NewTaggedCache[string, int](store)
+	c.PutWithTags("a", 4, "t1")
+	c.PutWithTags("b", 4, "t1")
+	store.Pin("a")
+	store.Pin("b")
+
+	//new value doesn't fit with pinned items, the old one keeps its tags
+	c.PutWithTags("a", 7, "t2")
+	r, _ := c.Get("a", 0)
+	s.Equal(4, r)
+	s.Equal([]string{"t1"}, c.Tags("a"))
+	s.Equal(2, c.InvalidateTag("t1"))
+	s.Equal(0, store.Len())
+}
+
+func (s *suiteTaggedCache) TestAcquireReplaceRelease() {
+	s.cache.PutWithTags("a", 1, "t1")
+	h, ok := s.cache.Acquire("a")
+	s.True(ok)
+	s.cache.PutWithTags("a", 2, "t2")
+	h.Release()
+	s.Equal([]string{"a"}, s.removed)
+	s.Equal([]string{"t2"}, s.cache.Tags("a"))
+
+	s.Equal(0, s.cache.InvalidateTag("t1"))
+	s.Equal(1, s.cache.InvalidateTag("t2"))
+	_, ok = s.cache.Get("a", 0)
+	s.False(ok)
+
+	_, ok = s.cache.Acquire("a")
+	s.False(ok)
+}
+
+func (s *suiteTaggedCache) TestConcurrentRelease() {
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				k := strconv.Itoa((g + i) % 5)
+				if h, ok := s.cache.Acquire(k); ok {
+					s.cache.PutWithTags(k, i, strconv.Itoa(i%3))
+					h.Release()
+				} else {
+					s.cache.PutWithTags(k, i, strconv.Itoa(i%3))
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	for key := range s.cache.keyTags {
+		s.True(s.cache.store.contains(key))
+	}
+}
+
+func (s *suiteTaggedCache) TestRandom() {
+	for i := 0; i < 1000; i++ {
+		k := strconv.Itoa(i % 7)
+		switch i % 5 {
+		case 0:
+			s.cache.InvalidateTag(strconv.Itoa(i % 3))
+		case 1:
+			s.cache.Delete(k)
+		default:
+			s.cache.PutWithTags(k, i, strconv.Itoa(i%3), strconv.Itoa(i%4))
+		}
+		//index has only resident keys
+		for key := range s.cache.keyTags {
+			s.True(s.cache.store.contains(key))
+		}
+		for tag, keys := range s.cache.tagKeys {
+			for key := range keys {
+				s.Contains(s.cache.keyTags[key], tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package trace
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BlockSize - size of one block in ARC and UMass traces
+const BlockSize = 512
+
+// NewARCReader - reader of ARC traces (Megiddo and Modha), every line
+// "startBlock blocksCount ignored requestNumber" expands into blocksCount requests of single blocks
+func NewARCReader(r io.Reader) Reader {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	return &reader{next: func(buf []Request) ([]Request, error) {
+		for scanner.Scan() {
+			line += 1
+			fields := strings.Fields(scanner.Text())
+			if 0 == len(fields) {
+				continue
+			}
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("arc trace line %d: expected at least 2 fields", line)
+			}
+			start, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("arc trace line %d: bad start block: %w", line, err)
+			}
+			count, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("arc trace line %d: bad blocks count: %w", line, err)
+			}
+			ts := int64(line)
+			if len(fields) > 3 {
+				if ts, err = strconv.ParseInt(fields[3], 10, 64); err != nil {
+					return nil, fmt.Errorf("arc trace line %d: bad request number: %w", line, err)
+				}
+			}
+			for i := uint64(0); i < count; i++ {
+				buf = append(buf, Request{
+					Timestamp: ts,
+					Key:       strconv.FormatUint(start+i, 10),
+					Size:      BlockSize,
+					Op:        OpGet,
+				})
+			}
+			if len(buf) > 0 {
+				return buf, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}}
+}
+
+// NewSPCReader - reader of UMass Trace Repository storage traces in SPC format
+// "ASU,LBA,size,opcode,timestamp". Requests are expanded into blocks with keys "ASU:LBA",
+// timestamp in seconds is converted into microseconds.
+func NewSPCReader(r io.Reader) Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	return &reader{next: func(buf []Request) ([]Request, error) {
+		for {
+			record, err := cr.Read()
+			if err != nil {
+				return nil, err
+			}
+			line, _ := cr.FieldPos(0)
+			if len(record) < 5 {
+				return nil, fmt.Errorf("spc trace line %d: expected 5 fields", line)
+			}
+			asu := strings.TrimSpace(record[0])
+			lba, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("spc trace line %d: bad LBA: %w", line, err)
+			}
+			size, err := strconv.ParseUint(strings.TrimSpace(record[2]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("spc trace line %d: bad size: %w", line, err)
+			}
+			op, err := ParseOp(record[3])
+			if err != nil {
+				return nil, fmt.Errorf("spc trace line %d: %w", line, err)
+			}
+			seconds, err := strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("spc trace line %d: bad timestamp: %w", line, err)
+			}
+			ts := int64(math.Round(seconds * 1e6))
+			blocks := (size + BlockSize - 1) / BlockSize
+			for i := uint64(0); i < blocks; i++ {
+				buf = append(buf, Request{
+					Timestamp: ts,
+					Key:       asu + ":" + strconv.FormatUint(lba+i, 10),
+					Size:      BlockSize,
+					Op:        op,
+				})
+			}
+			if len(buf) > 0 {
+				return buf, nil
+			}
+		}
+	}}
+}
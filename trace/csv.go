@@ -0,0 +1,173 @@
+package trace
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrNoKeyColumn = errors.New("meta trace header has no key column")
+
+func newCSVReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.TrimLeadingSpace = true
+	return cr
+}
+
+func parseUint(record []string, i int) (uint64, error) {
+	if i < 0 || i >= len(record) || "" == record[i] {
+		return 0, nil
+	}
+	return strconv.ParseUint(record[i], 10, 64)
+}
+
+func parseInt(record []string, i int) (int64, error) {
+	if i < 0 || i >= len(record) || "" == record[i] {
+		return 0, nil
+	}
+	return strconv.ParseInt(record[i], 10, 64)
+}
+
+// NewTwitterReader - reader of Twitter cache traces (twitter/cache-trace) with columns
+// "timestamp,key,keySize,valueSize,clientId,op,ttl", size of request is keySize+valueSize
+func NewTwitterReader(r io.Reader) Reader {
+	cr := newCSVReader(r)
+	return &reader{next: func(buf []Request) ([]Request, error) {
+		record, err := cr.Read()
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) < 6 {
+			return nil, fmt.Errorf("twitter trace line %d: expected at least 6 fields", line)
+		}
+		ts, err := parseInt(record, 0)
+		if err != nil {
+			return nil, fmt.Errorf("twitter trace line %d: bad timestamp: %w", line, err)
+		}
+		keySize, err := parseUint(record, 2)
+		if err != nil {
+			return nil, fmt.Errorf("twitter trace line %d: bad key size: %w", line, err)
+		}
+		valueSize, err := parseUint(record, 3)
+		if err != nil {
+			return nil, fmt.Errorf("twitter trace line %d: bad value size: %w", line, err)
+		}
+		op, err := ParseOp(record[5])
+		if err != nil {
+			return nil, fmt.Errorf("twitter trace line %d: %w", line, err)
+		}
+		return append(buf, Request{Timestamp: ts, Key: record[1], Size: keySize + valueSize, Op: op}), nil
+	}}
+}
+
+// NewMetaReader - reader of Meta CacheLib kvcache traces. Columns are found by the header:
+// key is required, op, op_time, size, key_size and op_count are optional.
+// Record with op_count N expands into N requests, size of request is key_size+size.
+func NewMetaReader(r io.Reader) Reader {
+	cr := newCSVReader(r)
+	columns := map[string]int{}
+	col := func(name string) int {
+		if i, ok := columns[name]; ok {
+			return i
+		}
+		return -1
+	}
+	return &reader{next: func(buf []Request) ([]Request, error) {
+		if 0 == len(columns) {
+			header, err := cr.Read()
+			if err != nil {
+				return nil, err
+			}
+			for i, name := range header {
+				columns[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			if col("key") < 0 {
+				return nil, ErrNoKeyColumn
+			}
+		}
+		for {
+			record, err := cr.Read()
+			if err != nil {
+				return nil, err
+			}
+			line, _ := cr.FieldPos(0)
+			if col("key") >= len(record) {
+				return nil, fmt.Errorf("meta trace line %d: no key", line)
+			}
+			req := Request{Key: record[col("key")], Size: 1, Op: OpGet}
+			if i := col("op_time"); i >= 0 {
+				if req.Timestamp, err = parseInt(record, i); err != nil {
+					return nil, fmt.Errorf("meta trace line %d: bad op_time: %w", line, err)
+				}
+			}
+			if i := col("op"); i >= 0 && i < len(record) {
+				if req.Op, err = ParseOp(record[i]); err != nil {
+					return nil, fmt.Errorf("meta trace line %d: %w", line, err)
+				}
+			}
+			if i := col("size"); i >= 0 {
+				size, err := parseUint(record, i)
+				if err != nil {
+					return nil, fmt.Errorf("meta trace line %d: bad size: %w", line, err)
+				}
+				keySize, err := parseUint(record, col("key_size"))
+				if err != nil {
+					return nil, fmt.Errorf("meta trace line %d: bad key_size: %w", line, err)
+				}
+				req.Size = keySize + size
+			}
+			count := uint64(1)
+			if i := col("op_count"); i >= 0 {
+				if count, err = parseUint(record, i); err != nil {
+					return nil, fmt.Errorf("meta trace line %d: bad op_count: %w", line, err)
+				}
+			}
+			for j := uint64(0); j < count; j++ {
+				buf = append(buf, req)
+			}
+			if len(buf) > 0 {
+				return buf, nil
+			}
+		}
+	}}
+}
+
+// NewCSVReader - reader of simple "timestamp,key,size,op" CSV, op is optional and defaults to get,
+// header line started with "timestamp" is skipped
+func NewCSVReader(r io.Reader) Reader {
+	cr := newCSVReader(r)
+	return &reader{next: func(buf []Request) ([]Request, error) {
+		for {
+			record, err := cr.Read()
+			if err != nil {
+				return nil, err
+			}
+			line, _ := cr.FieldPos(0)
+			if 1 == line && strings.EqualFold(record[0], "timestamp") {
+				continue
+			}
+			if len(record) < 3 {
+				return nil, fmt.Errorf("csv trace line %d: expected at least 3 fields", line)
+			}
+			req := Request{Key: record[1], Op: OpGet}
+			if req.Timestamp, err = parseInt(record, 0); err != nil {
+				return nil, fmt.Errorf("csv trace line %d: bad timestamp: %w", line, err)
+			}
+			if req.Size, err = parseUint(record, 2); err != nil {
+				return nil, fmt.Errorf("csv trace line %d: bad size: %w", line, err)
+			}
+			if len(record) > 3 && "" != record[3] {
+				if req.Op, err = ParseOp(record[3]); err != nil {
+					return nil, fmt.Errorf("csv trace line %d: %w", line, err)
+				}
+			}
+			return append(buf, req), nil
+		}
+	}}
+}
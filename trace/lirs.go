@@ -0,0 +1,41 @@
+package trace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NewLIRSReader - reader of LIRS-style traces: one key per line with optional size.
+// Empty lines, comments started with # and "*" separators are skipped, size defaults to 1,
+// timestamp is the number of request.
+func NewLIRSReader(r io.Reader) Reader {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	ts := int64(0)
+	return &reader{next: func(buf []Request) ([]Request, error) {
+		for scanner.Scan() {
+			line += 1
+			fields := strings.Fields(scanner.Text())
+			if 0 == len(fields) || "*" == fields[0] || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			ts += 1
+			req := Request{Timestamp: ts, Key: fields[0], Size: 1, Op: OpGet}
+			if len(fields) > 1 {
+				size, err := strconv.ParseUint(fields[1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("lirs trace line %d: bad size: %w", line, err)
+				}
+				req.Size = size
+			}
+			return append(buf, req), nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}}
+}
@@ -0,0 +1,197 @@
+// Package trace reads published cache and block traces as a stream of uniform requests.
+package trace
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type Op byte
+
+const (
+	OpGet Op = iota
+	OpSet
+	OpDelete
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpGet:
+		return "get"
+	case OpSet:
+		return "set"
+	case OpDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("Op(%d)", o)
+}
+
+// ParseOp - parse operation name of memcached-like and block traces, case insensitive
+func ParseOp(s string) (Op, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "get", "gets", "read", "r":
+		return OpGet, nil
+	case "set", "add", "replace", "cas", "append", "prepend", "incr", "decr", "write", "w":
+		return OpSet, nil
+	case "delete", "del", "d":
+		return OpDelete, nil
+	}
+	return OpGet, fmt.Errorf("unknown operation %q", s)
+}
+
+// Request - one request of the trace.
+// Timestamp is in units of the source trace, Size is in bytes or 1 if trace has no sizes.
+type Request struct {
+	Timestamp int64
+	Key       string
+	Size      uint64
+	Op        Op
+}
+
+// Reader - uniform iterator over trace requests, Read returns io.EOF at the end of the trace
+type Reader interface {
+	Read() (Request, error)
+}
+
+type ReadCloser interface {
+	Reader
+	io.Closer
+}
+
+type Format string
+
+const (
+	// FormatARC - ARC block traces: "startBlock blocksCount ignored requestNumber"
+	FormatARC Format = "arc"
+	// FormatSPC - UMass storage traces: "ASU,LBA,size,opcode,timestamp"
+	FormatSPC Format = "spc"
+	// FormatLIRS - LIRS traces: one key per line with optional size
+	FormatLIRS Format = "lirs"
+	// FormatTwitter - Twitter cache traces: "timestamp,key,keySize,valueSize,clientId,op,ttl"
+	FormatTwitter Format = "twitter"
+	// FormatMeta - Meta CacheLib kvcache traces with header, for example "op_time,key,key_size,op,op_count,size"
+	FormatMeta Format = "meta"
+	// FormatCSV - simple "timestamp,key,size,op" CSV
+	FormatCSV Format = "csv"
+)
+
+// Formats - all supported formats
+func Formats() []Format {
+	return []Format{FormatARC, FormatSPC, FormatLIRS, FormatTwitter, FormatMeta, FormatCSV}
+}
+
+// NewReader - create reader of trace in given format, gzip compressed input is detected by magic bytes
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	in, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatARC:
+		return NewARCReader(in), nil
+	case FormatSPC:
+		return NewSPCReader(in), nil
+	case FormatLIRS:
+		return NewLIRSReader(in), nil
+	case FormatTwitter:
+		return NewTwitterReader(in), nil
+	case FormatMeta:
+		return NewMetaReader(in), nil
+	case FormatCSV:
+		return NewCSVReader(in), nil
+	}
+	return nil, fmt.Errorf("unknown trace format %q", format)
+}
+
+type fileReader struct {
+	Reader
+	closers []io.Closer
+}
+
+func (f *fileReader) Close() error {
+	var result error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if err := f.closers[i].Close(); err != nil && nil == result {
+			result = err
+		}
+	}
+	return result
+}
+
+// Open - open trace file in given format, gzip compressed files are supported
+func Open(name string, format Format) (ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	result := &fileReader{closers: []io.Closer{f}}
+	in, err := decompress(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if c, ok := in.(io.Closer); ok {
+		result.closers = append(result.closers, c)
+	}
+	result.Reader, err = NewReader(in, format)
+	if err != nil {
+		_ = result.Close()
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReadAll - read all requests of the trace into memory
+func ReadAll(r Reader) ([]Request, error) {
+	var result []Request
+	for {
+		req, err := r.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		result = append(result, req)
+	}
+}
+
+func decompress(r io.Reader) (io.Reader, error) {
+	if _, ok := r.(*gzip.Reader); ok {
+		return r, nil
+	}
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	magic, err := br.Peek(2)
+	if err == nil && 0x1f == magic[0] && 0x8b == magic[1] {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// reader - expands records of the trace into requests, one record can produce several requests
+type reader struct {
+	next    func(buf []Request) ([]Request, error)
+	pending []Request
+	pos     int
+}
+
+func (r *reader) Read() (Request, error) {
+	for r.pos >= len(r.pending) {
+		pending, err := r.next(r.pending[:0])
+		if err != nil {
+			return Request{}, err
+		}
+		r.pending = pending
+		r.pos = 0
+	}
+	req := r.pending[r.pos]
+	r.pos += 1
+	return req, nil
+}
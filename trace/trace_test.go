@@ -0,0 +1,143 @@
+package trace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTrace struct {
+	suite.Suite
+}
+
+func TestTrace(t *testing.T) {
+	suite.Run(t, new(suiteTrace))
+}
+
+func (s *suiteTrace) read(format Format, data string) []Request {
+	r, err := NewReader(strings.NewReader(data), format)
+	s.Require().NoError(err)
+	result, err := ReadAll(r)
+	s.Require().NoError(err)
+	return result
+}
+
+func (s *suiteTrace) TestARC() {
+	result := s.read(FormatARC, "10 2 0 1\n\n7 1 0 2\n")
+	s.Equal([]Request{
+		{Timestamp: 1, Key: "10", Size: BlockSize},
+		{Timestamp: 1, Key: "11", Size: BlockSize},
+		{Timestamp: 2, Key: "7", Size: BlockSize},
+	}, result)
+
+	r := NewARCReader(strings.NewReader("x 1 0 1\n"))
+	_, err := r.Read()
+	s.Error(err)
+}
+
+func (s *suiteTrace) TestSPC() {
+	result := s.read(FormatSPC, "0,303567,1024,w,0.000000\n1,55,512,R,0.5\n")
+	s.Equal([]Request{
+		{Timestamp: 0, Key: "0:303567", Size: BlockSize, Op: OpSet},
+		{Timestamp: 0, Key: "0:303568", Size: BlockSize, Op: OpSet},
+		{Timestamp: 500000, Key: "1:55", Size: BlockSize, Op: OpGet},
+	}, result)
+}
+
+func (s *suiteTrace) TestLIRS() {
+	result := s.read(FormatLIRS, "# comment\n5\n*\n6 100\n\n5\n")
+	s.Equal([]Request{
+		{Timestamp: 1, Key: "5", Size: 1},
+		{Timestamp: 2, Key: "6", Size: 100},
+		{Timestamp: 3, Key: "5", Size: 1},
+	}, result)
+
+	r := NewLIRSReader(strings.NewReader("5 big\n"))
+	_, err := r.Read()
+	s.Error(err)
+}
+
+func (s *suiteTrace) TestTwitter() {
+	result := s.read(FormatTwitter, "0,q:q:1,16,100,1,get,0\n1,q:q:2,16,0,2,delete,0\n2,q:q:1,16,200,1,set,3600\n")
+	s.Equal([]Request{
+		{Timestamp: 0, Key: "q:q:1", Size: 116, Op: OpGet},
+		{Timestamp: 1, Key: "q:q:2", Size: 16, Op: OpDelete},
+		{Timestamp: 2, Key: "q:q:1", Size: 216, Op: OpSet},
+	}, result)
+
+	r := NewTwitterReader(strings.NewReader("0,k,1,1,1,touch,0\n"))
+	_, err := r.Read()
+	s.Error(err)
+}
+
+func (s *suiteTrace) TestMeta() {
+	result := s.read(FormatMeta, "op_time,key,key_size,op,op_count,size,cache_hits,ttl\n10,k1,2,GET,2,100,1,60\n11,k2,2,SET,1,50,0,60\n")
+	s.Equal([]Request{
+		{Timestamp: 10, Key: "k1", Size: 102, Op: OpGet},
+		{Timestamp: 10, Key: "k1", Size: 102, Op: OpGet},
+		{Timestamp: 11, Key: "k2", Size: 52, Op: OpSet},
+	}, result)
+
+	result = s.read(FormatMeta, "key,op,size,op_count,key_size\nk1,GET,10,1,2\nk2,GET,10,0,2\nk3,DELETE,0,1,2\n")
+	s.Equal([]Request{
+		{Key: "k1", Size: 12, Op: OpGet},
+		{Key: "k3", Size: 2, Op: OpDelete},
+	}, result)
+
+	r := NewMetaReader(strings.NewReader("op,size\nGET,1\n"))
+	_, err := r.Read()
+	s.ErrorIs(err, ErrNoKeyColumn)
+}
+
+func (s *suiteTrace) TestCSV() {
+	result := s.read(FormatCSV, "timestamp,key,size,op\n1,a,10,get\n2,b,20\n3,a,0,delete\n")
+	s.Equal([]Request{
+		{Timestamp: 1, Key: "a", Size: 10, Op: OpGet},
+		{Timestamp: 2, Key: "b", Size: 20, Op: OpGet},
+		{Timestamp: 3, Key: "a", Size: 0, Op: OpDelete},
+	}, result)
+}
+
+func (s *suiteTrace) TestGzip() {
+	buf := new(bytes.Buffer)
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write([]byte("1\n2\n1\n"))
+	s.Require().NoError(err)
+	s.Require().NoError(zw.Close())
+
+	name := filepath.Join(s.T().TempDir(), "trace.gz")
+	s.Require().NoError(os.WriteFile(name, buf.Bytes(), 0o600))
+
+	r, err := Open(name, FormatLIRS)
+	s.Require().NoError(err)
+	result, err := ReadAll(r)
+	s.NoError(err)
+	s.NoError(r.Close())
+	s.Len(result, 3)
+	s.Equal("2", result[1].Key)
+
+	r2, err := NewReader(bytes.NewReader(buf.Bytes()), FormatLIRS)
+	s.Require().NoError(err)
+	req, err := r2.Read()
+	s.NoError(err)
+	s.Equal("1", req.Key)
+}
+
+func (s *suiteTrace) TestEOFAndUnknownFormat() {
+	r, err := NewReader(strings.NewReader(""), FormatTwitter)
+	s.Require().NoError(err)
+	_, err = r.Read()
+	s.Equal(io.EOF, err)
+
+	_, err = NewReader(strings.NewReader(""), Format("bad"))
+	s.Error(err)
+	_, err = ParseOp("touch")
+	s.Error(err)
+	s.Equal("delete", OpDelete.String())
+}
@@ -0,0 +1,137 @@
+// Package tune searches constructor parameters of policies with the best hit ratio on a trace.
+package tune
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Strategy byte
+
+const (
+	// Grid - evaluate every combination of parameters
+	Grid Strategy = iota
+	// HillClimb - start from recommended parameters and move to the best neighbour while hit ratio grows
+	HillClimb
+)
+
+func ParseStrategy(s string) (Strategy, error) {
+	switch strings.ToLower(s) {
+	case "grid":
+		return Grid, nil
+	case "hill", "hillclimb":
+		return HillClimb, nil
+	}
+	return Grid, fmt.Errorf("unknown strategy %q", s)
+}
+
+// space - discrete parameter space, point is index of value in every dimension
+type space struct {
+	dims  []int
+	start []int
+	eval  func(point []int) (float64, error)
+
+	cache map[string]float64
+}
+
+func newSpace(dims, start []int, eval func(point []int) (float64, error)) *space {
+	return &space{dims: dims, start: start, eval: eval, cache: make(map[string]float64)}
+}
+
+func key(point []int) string {
+	b := make([]byte, 0, 4*len(point))
+	for _, i := range point {
+		b = strconv.AppendInt(b, int64(i), 10)
+		b = append(b, ',')
+	}
+	return string(b)
+}
+
+func (s *space) value(point []int) (float64, error) {
+	k := key(point)
+	if v, ok := s.cache[k]; ok {
+		return v, nil
+	}
+	v, err := s.eval(point)
+	if err != nil {
+		return 0, err
+	}
+	s.cache[k] = v
+	return v, nil
+}
+
+func (s *space) search(strategy Strategy) ([]int, float64, error) {
+	if HillClimb == strategy {
+		return s.hillClimb()
+	}
+	return s.grid()
+}
+
+// grid - start point wins ties, then the first point in order
+func (s *space) grid() ([]int, float64, error) {
+	best := append([]int(nil), s.start...)
+	bestValue, err := s.value(best)
+	if err != nil {
+		return nil, 0, err
+	}
+	point := make([]int, len(s.dims))
+	for {
+		v, err := s.value(point)
+		if err != nil {
+			return nil, 0, err
+		}
+		if v > bestValue {
+			best, bestValue = append(best[:0], point...), v
+		}
+		i := 0
+		for ; i < len(point); i++ {
+			point[i] += 1
+			if point[i] < s.dims[i] {
+				break
+			}
+			point[i] = 0
+		}
+		if i == len(point) {
+			return best, bestValue, nil
+		}
+	}
+}
+
+func (s *space) hillClimb() ([]int, float64, error) {
+	cur := append([]int(nil), s.start...)
+	curValue, err := s.value(cur)
+	if err != nil {
+		return nil, 0, err
+	}
+	for {
+		var next []int
+		nextValue := curValue
+		for i := range cur {
+			for _, step := range []int{-1, 1} {
+				j := cur[i] + step
+				if j < 0 || j >= s.dims[i] {
+					continue
+				}
+				n := append([]int(nil), cur...)
+				n[i] = j
+				v, err := s.value(n)
+				if err != nil {
+					return nil, 0, err
+				}
+				if v > nextValue {
+					next, nextValue = n, v
+				}
+			}
+		}
+		if nil == next {
+			return cur, curValue, nil
+		}
+		cur, curValue = next, nextValue
+	}
+}
+
+// Evaluated - number of distinct configurations replayed
+func (s *space) evaluated() int {
+	return len(s.cache)
+}
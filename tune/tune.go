@@ -0,0 +1,166 @@
+package tune
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/satmaelstorm/allcache"
+	"github.com/satmaelstorm/allcache/sim"
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+var ErrZeroCapacity = errors.New("capacity must be positive")
+
+// Result - the best parameters found and parameters recommended by the paper for comparison
+type Result[P fmt.Stringer] struct {
+	Params          P
+	HitRatio        float64
+	Default         P
+	DefaultHitRatio float64
+	Evaluated       int
+}
+
+func percent(capacity, p uint64) uint64 {
+	r := capacity * p / 100
+	if r < 1 {
+		return 1
+	}
+	return r
+}
+
+func hitRatio(requests []trace.Request, capacity uint64, newCache func() allcache.Cache[string, uint64]) (float64, error) {
+	p := sim.Policy{
+		Name: "tune",
+		New: func(uint64, bool) (allcache.Cache[string, uint64], error) {
+			return newCache(), nil
+		},
+	}
+	r, err := sim.Replay(requests, p, capacity, false)
+	if err != nil {
+		return 0, err
+	}
+	return r.HitRatio(), nil
+}
+
+type Full2QParams struct {
+	AmSize    uint64
+	A1InSize  uint64
+	A1OutSize uint64
+}
+
+func (p Full2QParams) String() string {
+	return fmt.Sprintf("allcache.NewFull2Q[K, T](%d, %d, %d)", p.AmSize, p.A1InSize, p.A1OutSize)
+}
+
+var (
+	// full2QKin - percents of capacity for A1in, paper recommends 25
+	full2QKin = []uint64{1, 5, 10, 15, 20, 25, 30, 40, 50, 60}
+	// full2QKout - percents of capacity for A1out, paper recommends 50
+	full2QKout = []uint64{10, 25, 50, 75, 100, 150, 200, 300, 400}
+)
+
+// Full2Q - search sizes of NewFull2Q with total capacity of A1in and Am
+func Full2Q(requests []trace.Request, capacity uint64, strategy Strategy) (Result[Full2QParams], error) {
+	var result Result[Full2QParams]
+	if 0 == capacity {
+		return result, ErrZeroCapacity
+	}
+	params := func(point []int) Full2QParams {
+		a1In := percent(capacity, full2QKin[point[0]])
+		if a1In >= capacity {
+			a1In = capacity - 1
+		}
+		return Full2QParams{
+			AmSize:    capacity - a1In,
+			A1InSize:  a1In,
+			A1OutSize: percent(capacity, full2QKout[point[1]]),
+		}
+	}
+	s := newSpace(
+		[]int{len(full2QKin), len(full2QKout)},
+		[]int{5, 2},
+		func(point []int) (float64, error) {
+			p := params(point)
+			return hitRatio(requests, capacity, func() allcache.Cache[string, uint64] {
+				return allcache.NewFull2Q[string, uint64](p.AmSize, p.A1InSize, p.A1OutSize)
+			})
+		},
+	)
+	best, value, err := s.search(strategy)
+	if err != nil {
+		return result, err
+	}
+	result.Params, result.HitRatio = params(best), value
+	result.Default = params(s.start)
+	result.DefaultHitRatio, _ = s.value(s.start)
+	result.Evaluated = s.evaluated()
+	return result, nil
+}
+
+type MQParams struct {
+	Queues   byte
+	MaxSize  uint64
+	QOutSize uint64
+	LifeTime uint64
+	// QueuesNum - name of QueuesNumCalculator: Log2QueuesNum or LinearQueuesNum
+	QueuesNum string
+}
+
+func (p MQParams) String() string {
+	return fmt.Sprintf(
+		"allcache.NewMQCache[K, T](%d, %d, %d, %d, allcache.%s, nil)",
+		p.Queues, p.MaxSize, p.QOutSize, p.LifeTime, p.QueuesNum,
+	)
+}
+
+var (
+	mqQueues = []byte{2, 4, 6, 8, 10, 12, 16}
+	// mqQOut - qOut size in capacities, paper recommends 4
+	mqQOut = []uint64{1, 2, 4, 8}
+	// mqLifeTime - lifeTime in percents of capacity
+	mqLifeTime   = []uint64{25, 50, 100, 200, 400, 800, 1600}
+	mqQueuesNums = []struct {
+		name string
+		calc allcache.QueuesNumCalculator
+	}{
+		{"Log2QueuesNum", allcache.Log2QueuesNum},
+		{"LinearQueuesNum", allcache.LinearQueuesNum},
+	}
+)
+
+// MQ - search parameters of NewMQCache with given capacity
+func MQ(requests []trace.Request, capacity uint64, strategy Strategy) (Result[MQParams], error) {
+	var result Result[MQParams]
+	if 0 == capacity {
+		return result, ErrZeroCapacity
+	}
+	params := func(point []int) MQParams {
+		return MQParams{
+			Queues:    mqQueues[point[0]],
+			MaxSize:   capacity,
+			QOutSize:  capacity * mqQOut[point[1]],
+			LifeTime:  percent(capacity, mqLifeTime[point[2]]),
+			QueuesNum: mqQueuesNums[point[3]].name,
+		}
+	}
+	s := newSpace(
+		[]int{len(mqQueues), len(mqQOut), len(mqLifeTime), len(mqQueuesNums)},
+		[]int{3, 2, 2, 0},
+		func(point []int) (float64, error) {
+			p := params(point)
+			calc := mqQueuesNums[point[3]].calc
+			return hitRatio(requests, capacity, func() allcache.Cache[string, uint64] {
+				return allcache.NewMQCache[string, uint64](p.Queues, p.MaxSize, p.QOutSize, p.LifeTime, calc, nil)
+			})
+		},
+	)
+	best, value, err := s.search(strategy)
+	if err != nil {
+		return result, err
+	}
+	result.Params, result.HitRatio = params(best), value
+	result.Default = params(s.start)
+	result.DefaultHitRatio, _ = s.value(s.start)
+	result.Evaluated = s.evaluated()
+	return result, nil
+}
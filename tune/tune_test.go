@@ -0,0 +1,79 @@
+package tune
+
+import (
+	"testing"
+
+	"github.com/satmaelstorm/allcache/trace"
+	"github.com/satmaelstorm/allcache/workload"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteTune struct {
+	suite.Suite
+	requests []trace.Request
+}
+
+func TestTune(t *testing.T) {
+	suite.Run(t, new(suiteTune))
+}
+
+func (s *suiteTune) SetupSuite() {
+	requests, err := trace.ReadAll(workload.Reader(workload.NewScanMix(1, 50, 150, 200), 5000))
+	s.Require().NoError(err)
+	s.requests = requests
+}
+
+func (s *suiteTune) TestFull2Q() {
+	grid, err := Full2Q(s.requests, 100, Grid)
+	s.Require().NoError(err)
+	s.Equal(len(full2QKin)*len(full2QKout), grid.Evaluated)
+	s.GreaterOrEqual(grid.HitRatio, grid.DefaultHitRatio)
+	s.Equal(Full2QParams{AmSize: 75, A1InSize: 25, A1OutSize: 50}, grid.Default)
+	s.Equal(uint64(100), grid.Params.AmSize+grid.Params.A1InSize)
+
+	hill, err := Full2Q(s.requests, 100, HillClimb)
+	s.Require().NoError(err)
+	s.Less(hill.Evaluated, grid.Evaluated)
+	s.GreaterOrEqual(hill.HitRatio, hill.DefaultHitRatio)
+	s.LessOrEqual(hill.HitRatio, grid.HitRatio)
+
+	_, err = Full2Q(s.requests, 0, Grid)
+	s.ErrorIs(err, ErrZeroCapacity)
+}
+
+func (s *suiteTune) TestMQ() {
+	hill, err := MQ(s.requests, 100, HillClimb)
+	s.Require().NoError(err)
+	s.GreaterOrEqual(hill.HitRatio, hill.DefaultHitRatio)
+	s.Equal(
+		"allcache.NewMQCache[K, T](8, 100, 400, 100, allcache.Log2QueuesNum, nil)",
+		hill.Default.String(),
+	)
+	s.Equal(uint64(100), hill.Params.MaxSize)
+}
+
+func (s *suiteTune) TestSearch() {
+	// single maximum at (2, 1)
+	sp := newSpace([]int{4, 3}, []int{0, 0}, func(p []int) (float64, error) {
+		return -float64((p[0]-2)*(p[0]-2) + (p[1]-1)*(p[1]-1)), nil
+	})
+	best, v, err := sp.search(HillClimb)
+	s.NoError(err)
+	s.Equal([]int{2, 1}, best)
+	s.Equal(0.0, v)
+
+	sp = newSpace([]int{4, 3}, []int{0, 0}, func(p []int) (float64, error) {
+		return float64(p[0] * p[1]), nil
+	})
+	best, v, err = sp.search(Grid)
+	s.NoError(err)
+	s.Equal([]int{3, 2}, best)
+	s.Equal(6.0, v)
+	s.Equal(12, sp.evaluated())
+
+	_, err = ParseStrategy("random")
+	s.Error(err)
+	st, err := ParseStrategy("hill")
+	s.NoError(err)
+	s.Equal(HillClimb, st)
+}
@@ -2,10 +2,112 @@ package allcache
 
 type SizeCalculator[T any] func(T) uint64
 
+// CostCalculator - cost of fetching the item again after eviction, e.g. latency or 1 for hit ratio
+type CostCalculator[T any] func(T) uint64
+
 type QueuesNumCalculator func(hits uint64) byte
 
+// KeyHasher - 64 bit hash of key, used by sketches and filters of admitters
+type KeyHasher[K comparable] func(K) uint64
+
 type Cache[K comparable, T any] interface {
+	// Put - put item, cache can silently not keep it: item can be too large, not admitted
+	// or not fit with pinned items of Pinner, Store.TryPut reports the last case with error
 	Put(key K, item T)
 	Get(key K, def T) (T, bool)
 	Delete(key K)
 }
+
+// BulkDeleter - cache which can delete many items at once, all caches of the package implement it,
+// including Store, TaggedCache and NegativeCache.
+// Predicate is called under lock of the cache and must not use the cache.
+type BulkDeleter[K comparable, T any] interface {
+	// DeleteFunc - delete items for which f returns true, returns number of deleted items
+	DeleteFunc(f func(key K, value T) bool) int
+}
+
+// Pinner - cache which can exempt items from eviction. Store and caches built on it implement it:
+// LRU, Simplified2Q, Full2Q, MQ and LFU. LIRS, Clock, ClockPro, S3FIFO, Sieve, GDSF, LRUK,
+// SLRU and SampledCache don't support pinning.
+// Put of item which doesn't fit with pinned items silently drops it, TryPut of Store returns ErrPinnedOverCapacity.
+type Pinner[K comparable] interface {
+	// Pin - the item is not evicted until Unpin, false if there is no such item
+	Pin(key K) bool
+	// Unpin - false if there is no such pinned item
+	Unpin(key K) bool
+}
+
+// RemoveReason - why the key was removed from Store
+type RemoveReason byte
+
+const (
+	// RemoveDeleted - key was deleted by Delete
+	RemoveDeleted RemoveReason = iota
+	// RemoveEvicted - key was chosen by Policy.Victim to free space
+	RemoveEvicted
+	// RemoveExpired - TTL of the key is over
+	RemoveExpired
+	// RemovePinned - key is pinned or acquired and stays in Store, but leaves the policy until it is released.
+	// HoldingPolicy never gets it.
+	RemovePinned
+	// RemoveReplaced - value of acquired key was replaced, eviction callback gets the old value
+	// after its last handle is released. Policy never gets it, replaced value of other key is not reported.
+	RemoveReplaced
+)
+
+func (r RemoveReason) String() string {
+	switch r {
+	case RemoveDeleted:
+		return "deleted"
+	case RemoveEvicted:
+		return "evicted"
+	case RemoveExpired:
+		return "expired"
+	case RemovePinned:
+		return "pinned"
+	case RemoveReplaced:
+		return "replaced"
+	}
+	return "unknown"
+}
+
+// Policy - eviction policy of Store. Policy tracks keys only, values and weights are kept by Store.
+// Store calls policy under its lock, so policy needs no synchronization.
+type Policy[K comparable] interface {
+	// OnInsert - key is added or its value is replaced, weight is the new weight of the key
+	OnInsert(key K, weight uint64)
+	// OnAccess - resident key is read
+	OnAccess(key K)
+	// OnRemove - key is removed from Store, pinned or acquired. It is also called by Delete of absent key,
+	// so policy can forget history of the key, e.g. ghost entries. Released key comes back by OnInsert.
+	OnRemove(key K, reason RemoveReason)
+	// Victim - key which should be evicted to free space, it is not removed until OnRemove.
+	// False if policy has nothing to evict.
+	Victim() (K, bool)
+}
+
+// HoldingPolicy - policy which keeps history of pinned and acquired keys. Store calls OnHold and OnUnhold
+// instead of OnRemove with RemovePinned and OnInsert, and other methods are called for held key as usual.
+// All policies of the package implement it, other policies lose history of the key, when it is held.
+type HoldingPolicy[K comparable] interface {
+	Policy[K]
+	// OnHold - key is pinned or acquired, Victim must skip it until OnUnhold
+	OnHold(key K)
+	// OnUnhold - key is unpinned and released, it can be the victim again
+	OnUnhold(key K)
+}
+
+// EvictionCallback - called after the key was removed from Store for any reason, outside of Store lock.
+// For acquired item it is called after the last handle is released.
+type EvictionCallback[K comparable, T any] func(key K, value T, reason RemoveReason)
+
+// Admitter - admission policy of Store, it decides whether new key is worth to be cached.
+// Store calls admitter under its lock, so admitter needs no synchronization, but it must not be shared by stores.
+type Admitter[K comparable] interface {
+	// Record - key is read by Get, hit or miss
+	Record(key K)
+	// Admit - candidate with weight is going to be inserted. Victim is the key which policy would evict
+	// to free space, hasVictim is false if candidate fits without eviction.
+	// Replacing of value of resident key is always admitted.
+	Admit(candidate K, weight uint64, victim K, hasVictim bool) bool
+}
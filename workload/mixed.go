@@ -0,0 +1,87 @@
+package workload
+
+import (
+	"math/rand"
+)
+
+// ScanMix - hot set [0, hotKeys) accessed uniformly, after every scanEvery hot accesses
+// a sequential scan of scanLength keys never seen before is inserted
+type ScanMix struct {
+	rnd        *rand.Rand
+	hotKeys    uint64
+	scanLength uint64
+	scanEvery  uint64
+
+	hotLeft  uint64
+	scanLeft uint64
+	nextScan uint64
+}
+
+// NewScanMix - panics if hotKeys is 0
+func NewScanMix(seed int64, hotKeys, scanLength, scanEvery uint64) *ScanMix {
+	mustPositive("ScanMix", "hotKeys", hotKeys)
+	return &ScanMix{
+		rnd:        rand.New(rand.NewSource(seed)),
+		hotKeys:    hotKeys,
+		scanLength: scanLength,
+		scanEvery:  scanEvery,
+		hotLeft:    scanEvery,
+		nextScan:   hotKeys,
+	}
+}
+
+func (g *ScanMix) Next() uint64 {
+	if 0 == g.hotLeft && 0 == g.scanLeft {
+		g.hotLeft = g.scanEvery
+		g.scanLeft = g.scanLength
+	}
+	if g.scanLeft > 0 {
+		g.scanLeft -= 1
+		r := g.nextScan
+		g.nextScan += 1
+		return r
+	}
+	if g.hotLeft > 0 {
+		g.hotLeft -= 1
+	}
+	return uint64(g.rnd.Int63n(int64(g.hotKeys)))
+}
+
+// Hotspot - keys in [0, keys), with probability hotRate key is taken uniformly
+// from window of hotKeys keys, which shifts by hotKeys every period requests
+type Hotspot struct {
+	rnd      *rand.Rand
+	keys     uint64
+	hotKeys  uint64
+	hotRate  float64
+	period   uint64
+	requests uint64
+}
+
+// NewHotspot - panics if keys, hotKeys or period is 0
+func NewHotspot(seed int64, keys, hotKeys uint64, hotRate float64, period uint64) *Hotspot {
+	mustPositive("Hotspot", "keys", keys)
+	mustPositive("Hotspot", "hotKeys", hotKeys)
+	mustPositive("Hotspot", "period", period)
+	return &Hotspot{
+		rnd:     rand.New(rand.NewSource(seed)),
+		keys:    keys,
+		hotKeys: hotKeys,
+		hotRate: hotRate,
+		period:  period,
+	}
+}
+
+// HotStart - first key of current hot window
+func (g *Hotspot) HotStart() uint64 {
+	return (g.requests / g.period) * g.hotKeys % g.keys
+}
+
+func (g *Hotspot) Next() uint64 {
+	start := g.HotStart()
+	g.requests += 1
+	if g.rnd.Float64() < g.hotRate {
+		return (start + uint64(g.rnd.Int63n(int64(g.hotKeys)))) % g.keys
+	}
+	return uint64(g.rnd.Int63n(int64(g.keys)))
+}
@@ -0,0 +1,78 @@
+// Package workload generates reproducible synthetic key streams for benchmarks and simulations.
+package workload
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+
+	"github.com/satmaelstorm/allcache/trace"
+)
+
+// Generator - infinite stream of key ids
+type Generator interface {
+	Next() uint64
+}
+
+type generatorReader struct {
+	g     Generator
+	left  int
+	count int64
+}
+
+func (r *generatorReader) Read() (trace.Request, error) {
+	if r.left <= 0 {
+		return trace.Request{}, io.EOF
+	}
+	r.left -= 1
+	r.count += 1
+	return trace.Request{Timestamp: r.count, Key: strconv.FormatUint(r.g.Next(), 10), Size: 1}, nil
+}
+
+// Reader - first n keys of the generator as trace of get requests with size 1
+func Reader(g Generator, n int) trace.Reader {
+	return &generatorReader{g: g, left: n}
+}
+
+// mustPositive - generators panic on empty key range or zero period, they have no way to return error from Next
+func mustPositive(generator, param string, n uint64) {
+	if 0 == n || n > math.MaxInt64 {
+		panic(fmt.Sprintf("workload: %s %s must be in [1, %d], got %d", generator, param, uint64(math.MaxInt64), n))
+	}
+}
+
+// Uniform - keys uniformly distributed in [0, keys)
+type Uniform struct {
+	rnd  *rand.Rand
+	keys uint64
+}
+
+// NewUniform - panics if keys is 0
+func NewUniform(seed int64, keys uint64) *Uniform {
+	mustPositive("Uniform", "keys", keys)
+	return &Uniform{rnd: rand.New(rand.NewSource(seed)), keys: keys}
+}
+
+func (g *Uniform) Next() uint64 {
+	return uint64(g.rnd.Int63n(int64(g.keys)))
+}
+
+// Loop - keys 0, 1, ..., keys-1 repeated again and again
+type Loop struct {
+	keys uint64
+	cur  uint64
+}
+
+// NewLoop - panics if keys is 0
+func NewLoop(keys uint64) *Loop {
+	mustPositive("Loop", "keys", keys)
+	return &Loop{keys: keys}
+}
+
+func (g *Loop) Next() uint64 {
+	r := g.cur
+	g.cur = (g.cur + 1) % g.keys
+	return r
+}
@@ -0,0 +1,109 @@
+package workload
+
+import (
+	"io"
+	"testing"
+
+	"github.com/satmaelstorm/allcache/trace"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteWorkload struct {
+	suite.Suite
+}
+
+func TestWorkload(t *testing.T) {
+	suite.Run(t, new(suiteWorkload))
+}
+
+func take(g Generator, n int) []uint64 {
+	result := make([]uint64, n)
+	for i := range result {
+		result[i] = g.Next()
+	}
+	return result
+}
+
+func (s *suiteWorkload) TestSeeded() {
+	s.Equal(take(NewZipf(1, 100, 0.9), 50), take(NewZipf(1, 100, 0.9), 50))
+	s.NotEqual(take(NewZipf(1, 100, 0.9), 50), take(NewZipf(2, 100, 0.9), 50))
+	s.Equal(take(NewUniform(1, 100), 50), take(NewUniform(1, 100), 50))
+	s.Equal(take(NewHotspot(3, 100, 10, 0.9, 20), 50), take(NewHotspot(3, 100, 10, 0.9, 20), 50))
+}
+
+func (s *suiteWorkload) TestZipf() {
+	counts := make([]int, 100)
+	for _, k := range take(NewZipf(1, 100, 1.2), 10000) {
+		s.Less(k, uint64(100))
+		counts[k] += 1
+	}
+	s.Greater(counts[0], counts[1])
+	s.Greater(counts[1], counts[10])
+	s.Greater(counts[10], counts[99])
+
+	counts = make([]int, 4)
+	for _, k := range take(NewZipf(1, 4, 0), 10000) {
+		counts[k] += 1
+	}
+	for _, c := range counts {
+		s.InDelta(2500, c, 200)
+	}
+}
+
+func (s *suiteWorkload) TestLoop() {
+	s.Equal([]uint64{0, 1, 2, 0, 1, 2, 0}, take(NewLoop(3), 7))
+}
+
+func (s *suiteWorkload) TestScanMix() {
+	keys := take(NewScanMix(1, 10, 3, 4), 14)
+	for _, k := range keys[:4] {
+		s.Less(k, uint64(10))
+	}
+	s.Equal([]uint64{10, 11, 12}, keys[4:7])
+	for _, k := range keys[7:11] {
+		s.Less(k, uint64(10))
+	}
+	s.Equal([]uint64{13, 14, 15}, keys[11:14])
+
+	s.Equal([]uint64{5, 6, 7}, take(NewScanMix(1, 5, 3, 0), 3))
+}
+
+func (s *suiteWorkload) TestHotspot() {
+	g := NewHotspot(1, 100, 10, 1, 5)
+	for _, k := range take(g, 5) {
+		s.Less(k, uint64(10))
+	}
+	s.Equal(uint64(10), g.HotStart())
+	for _, k := range take(g, 5) {
+		s.GreaterOrEqual(k, uint64(10))
+		s.Less(k, uint64(20))
+	}
+}
+
+func (s *suiteWorkload) TestReader() {
+	requests, err := trace.ReadAll(Reader(NewLoop(2), 3))
+	s.NoError(err)
+	s.Equal([]trace.Request{
+		{Timestamp: 1, Key: "0", Size: 1},
+		{Timestamp: 2, Key: "1", Size: 1},
+		{Timestamp: 3, Key: "0", Size: 1},
+	}, requests)
+
+	r := Reader(NewLoop(2), 0)
+	_, err = r.Read()
+	s.Equal(io.EOF, err)
+}
+
+func (s *suiteWorkload) TestEmptyRange() {
+	s.PanicsWithValue("workload: Uniform keys must be in [1, 9223372036854775807], got 0", func() { NewUniform(1, 0) })
+	s.Panics(func() { NewLoop(0) })
+	s.Panics(func() { NewZipf(1, 0, 0.9) })
+	s.Panics(func() { NewScanMix(1, 0, 10, 10) })
+	s.Panics(func() { NewHotspot(1, 0, 10, 0.9, 20) })
+	s.Panics(func() { NewHotspot(1, 100, 0, 0.9, 20) })
+	s.PanicsWithValue("workload: Hotspot period must be in [1, 9223372036854775807], got 0", func() {
+		NewHotspot(1, 100, 10, 0.9, 0)
+	})
+	s.Panics(func() { NewUniform(1, 1<<63) })
+	s.NotPanics(func() { NewScanMix(1, 10, 0, 0).Next() })
+}
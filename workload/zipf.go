@@ -0,0 +1,38 @@
+package workload
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Zipf - keys in [0, keys) where probability of key k is proportional to 1/(k+1)^skew.
+// Unlike rand.Zipf any skew >= 0 is allowed, skew 0 is uniform distribution.
+type Zipf struct {
+	rnd *rand.Rand
+	cdf []float64
+}
+
+// NewZipf - panics if keys is 0
+func NewZipf(seed int64, keys uint64, skew float64) *Zipf {
+	mustPositive("Zipf", "keys", keys)
+	cdf := make([]float64, keys)
+	sum := 0.0
+	for k := uint64(0); k < keys; k++ {
+		sum += 1 / math.Pow(float64(k+1), skew)
+		cdf[k] = sum
+	}
+	for k := range cdf {
+		cdf[k] /= sum
+	}
+	return &Zipf{rnd: rand.New(rand.NewSource(seed)), cdf: cdf}
+}
+
+func (g *Zipf) Next() uint64 {
+	u := g.rnd.Float64()
+	k := sort.SearchFloat64s(g.cdf, u)
+	if k >= len(g.cdf) {
+		k = len(g.cdf) - 1
+	}
+	return uint64(k)
+}